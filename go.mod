@@ -1,11 +1,12 @@
 module github.com/opoccomaxao/tg-instrumentation
 
-go 1.23
+go 1.23.0
 
 require (
 	github.com/go-telegram/bot v1.13.3
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/text v0.28.0
 )
 
 require (
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/opoccomaxao/tg-instrumentation/storage"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxCallbackDataSize is the limit of callback_data in inline keyboard buttons.
+	MaxCallbackDataSize = 64
+
+	defaultTokenPrefix = "~"
+	defaultTokenTTL    = 30 * 24 * time.Hour
+	tokenHashSize      = 12
+	tokenKeyPrefix     = "callback:"
+)
+
+// EncodeCallbackData encodes the query and checks that it fits into callback_data.
+func (q *Query) EncodeCallbackData() (string, error) {
+	res := q.Encode()
+	if len(res) > MaxCallbackDataSize {
+		return "", errors.Wrapf(ErrTooLong, "callback data is %d bytes, limit is %d", len(res), MaxCallbackDataSize)
+	}
+
+	return res, nil
+}
+
+type CallbackEncoderOption func(*CallbackEncoder)
+
+// WithTokenPrefix sets the prefix that distinguishes tokens from regular queries. Default is "~".
+func WithTokenPrefix(prefix string) CallbackEncoderOption {
+	return func(e *CallbackEncoder) {
+		e.prefix = prefix
+	}
+}
+
+// WithTokenTTL sets how long the stored queries are kept. Default is 30 days.
+// Buttons with expired tokens cannot be expanded.
+func WithTokenTTL(ttl time.Duration) CallbackEncoderOption {
+	return func(e *CallbackEncoder) {
+		e.ttl = ttl
+	}
+}
+
+// CallbackEncoder encodes queries into callback_data.
+//
+// Queries longer than MaxCallbackDataSize are saved to the storage
+// and replaced with a short token. Expand restores the original query from the token.
+// The same query always produces the same token.
+type CallbackEncoder struct {
+	store  storage.Storage
+	prefix string
+	ttl    time.Duration
+}
+
+func NewCallbackEncoder(store storage.Storage, opts ...CallbackEncoderOption) *CallbackEncoder {
+	res := &CallbackEncoder{
+		store:  store,
+		prefix: defaultTokenPrefix,
+		ttl:    defaultTokenTTL,
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// Encode returns the encoded query if it fits into callback_data, otherwise the token.
+func (e *CallbackEncoder) Encode(ctx context.Context, q *Query) (string, error) {
+	return e.EncodeString(ctx, q.Encode())
+}
+
+// EncodeString returns the data if it fits into callback_data, otherwise the token.
+func (e *CallbackEncoder) EncodeString(ctx context.Context, data string) (string, error) {
+	if len(data) <= MaxCallbackDataSize && !e.IsToken(data) {
+		return data, nil
+	}
+
+	hash := sha256.Sum256([]byte(data))
+	token := e.prefix + base64.RawURLEncoding.EncodeToString(hash[:tokenHashSize])
+
+	err := e.store.Set(ctx, tokenKeyPrefix+token, []byte(data), e.ttl)
+	if err != nil {
+		return "", errors.WithMessage(err, "save callback data")
+	}
+
+	return token, nil
+}
+
+// IsToken reports whether the data is a token produced by the encoder.
+func (e *CallbackEncoder) IsToken(data string) bool {
+	return strings.HasPrefix(data, e.prefix)
+}
+
+// Expand returns the original data for the token. Other data is returned as is.
+// Returns ErrExpired if the token is not found in the storage.
+func (e *CallbackEncoder) Expand(ctx context.Context, data string) (string, error) {
+	if !e.IsToken(data) {
+		return data, nil
+	}
+
+	res, ok, err := e.store.Get(ctx, tokenKeyPrefix+data)
+	if err != nil {
+		return "", errors.WithMessage(err, "load callback data")
+	}
+
+	if !ok {
+		return "", errors.Wrapf(ErrExpired, "callback data token %s", data)
+	}
+
+	return string(res), nil
+}
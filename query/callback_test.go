@@ -0,0 +1,60 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opoccomaxao/tg-instrumentation/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery_EncodeCallbackData(t *testing.T) {
+	res, err := Command("short").WithParamInt64("id", 1).EncodeCallbackData()
+	require.NoError(t, err)
+	require.Equal(t, "short id=1", res)
+
+	_, err = Command("long").WithParam("text", strings.Repeat("a", MaxCallbackDataSize)).EncodeCallbackData()
+	require.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestCallbackEncoder(t *testing.T) {
+	ctx := context.Background()
+	encoder := NewCallbackEncoder(storage.NewMemory())
+
+	short := Command("short").WithParamInt64("id", 1)
+
+	res, err := encoder.Encode(ctx, short)
+	require.NoError(t, err)
+	require.Equal(t, "short id=1", res)
+
+	expanded, err := encoder.Expand(ctx, res)
+	require.NoError(t, err)
+	require.Equal(t, res, expanded)
+
+	long := Command("long").WithParam("text", strings.Repeat("a", MaxCallbackDataSize))
+
+	token, err := encoder.Encode(ctx, long)
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(token), MaxCallbackDataSize)
+	require.True(t, encoder.IsToken(token))
+
+	again, err := encoder.Encode(ctx, long)
+	require.NoError(t, err)
+	require.Equal(t, token, again)
+
+	expanded, err = encoder.Expand(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, long.Encode(), expanded)
+
+	_, err = NewCallbackEncoder(storage.NewMemory()).Expand(ctx, token)
+	require.ErrorIs(t, err, ErrExpired)
+
+	prefixed, err := encoder.EncodeString(ctx, "~short")
+	require.NoError(t, err)
+	require.NotEqual(t, "~short", prefixed)
+
+	expanded, err = encoder.Expand(ctx, prefixed)
+	require.NoError(t, err)
+	require.Equal(t, "~short", expanded)
+}
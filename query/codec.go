@@ -0,0 +1,304 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CompactPrefix marks the data encoded by CompactCodec.
+// Decode detects the format by the prefix, the version byte and the canonical form of the payload.
+const CompactPrefix = "-"
+
+const (
+	compactVersion   = 0xc1
+	compactAliasFlag = 1
+	compactIntValue  = 0
+)
+
+// Codec converts queries to strings and back.
+// TextCodec is readable, CompactCodec is short and fits into deep-link payloads.
+// Both formats are decoded by any codec, so they can be used together.
+type Codec interface {
+	Encode(q *Query) string
+	Decode(data string) *Query
+}
+
+// CompactDecoder is implemented by codecs that decode only the compact form, see CompactCodec.DecodeCompact.
+type CompactDecoder interface {
+	DecodeCompact(data string) (*Query, error)
+}
+
+// TextCodec is the default space-separated encoding, see Query.Encode.
+type TextCodec struct{}
+
+func (TextCodec) Encode(q *Query) string {
+	return q.Encode()
+}
+
+func (TextCodec) Decode(data string) *Query {
+	return Decode(data)
+}
+
+//nolint:gochecknoglobals
+var defaultCompactCodec = NewCompactCodec()
+
+type CompactCodecOption func(*CompactCodec)
+
+// WithKeyAliases sets the commands and keys replaced with one byte aliases.
+// The alias is the index in the list, so new keys must be appended to the end
+// to keep the existing data valid.
+func WithKeyAliases(keys ...string) CompactCodecOption {
+	return func(c *CompactCodec) {
+		c.keys = keys
+		c.aliases = make(map[string]uint64, len(keys))
+
+		for i, key := range keys {
+			c.aliases[key] = uint64(i)
+		}
+	}
+}
+
+// CompactCodec encodes queries into base64url binary form prefixed with CompactPrefix.
+// The binary form starts with the version byte, integers are packed as varints,
+// keys from WithKeyAliases are packed as aliases.
+// The result contains only [A-Za-z0-9_-] and can be used as a deep-link start parameter.
+type CompactCodec struct {
+	keys    []string
+	aliases map[string]uint64
+}
+
+func NewCompactCodec(opts ...CompactCodecOption) *CompactCodec {
+	res := &CompactCodec{}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// IsCompact reports whether the data may be encoded by CompactCodec.
+// It only checks the prefix, Decode falls back to text for data that does not validate.
+func IsCompact(data string) bool {
+	return len(data) > len(CompactPrefix) && strings.HasPrefix(data, CompactPrefix)
+}
+
+// Encode returns the compact form of the query. The command goes first, then params sorted by key.
+func (c *CompactCodec) Encode(q *Query) string {
+	res := []byte{compactVersion}
+
+	if q.Command != "" {
+		res = c.appendEntry(res, q.Command, q.Params[q.Command])
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(q.Params)) {
+		if key != q.Command {
+			res = c.appendEntry(res, key, q.Params[key])
+		}
+	}
+
+	return CompactPrefix + base64.RawURLEncoding.EncodeToString(res)
+}
+
+// EncodeDeepLink encodes the query into the start parameter of a deep link.
+// Returns ErrTooLong if the result exceeds 64 characters.
+func (c *CompactCodec) EncodeDeepLink(q *Query) (string, error) {
+	res := c.Encode(q)
+	if len(res) > MaxCallbackDataSize {
+		return "", errors.Wrapf(ErrTooLong, "deep link payload is %d bytes, limit is %d", len(res), MaxCallbackDataSize)
+	}
+
+	return res, nil
+}
+
+// Decode decodes the compact form. Other data is decoded as text, see Decode.
+// Only the exact form produced by Encode is decoded as compact,
+// so texts that merely start with CompactPrefix are kept as is.
+func (c *CompactCodec) Decode(data string) *Query {
+	res := New()
+	if !IsCompact(data) || c.decode(res, data) != nil {
+		res.decodeText(data)
+	}
+
+	return res
+}
+
+// DecodeCompact decodes only the exact form produced by Encode.
+// Returns ErrInvalidValue for other data, including texts that merely start with CompactPrefix.
+func (c *CompactCodec) DecodeCompact(data string) (*Query, error) {
+	if !IsCompact(data) {
+		return nil, errors.Wrap(ErrInvalidValue, "query has no compact prefix")
+	}
+
+	res := New()
+
+	err := c.decode(res, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// DecodeCompact decodes the data encoded by CompactCodec without key aliases.
+// See CompactCodec.DecodeCompact.
+func DecodeCompact(data string) (*Query, error) {
+	return defaultCompactCodec.DecodeCompact(data)
+}
+
+// entry: uvarint key header, uvarint values count, values.
+// Key header is alias<<1|1 or len(key)<<1 followed by the key.
+func (c *CompactCodec) appendEntry(res []byte, key string, values []string) []byte {
+	if alias, ok := c.aliases[key]; ok {
+		res = binary.AppendUvarint(res, alias<<1|compactAliasFlag)
+	} else {
+		res = binary.AppendUvarint(res, uint64(len(key))<<1)
+		res = append(res, key...)
+	}
+
+	res = binary.AppendUvarint(res, uint64(len(values)))
+
+	for _, value := range values {
+		res = appendCompactValue(res, value)
+	}
+
+	return res
+}
+
+// appendCompactValue writes 0 and varint for canonical integers, len(value)+1 and the value otherwise.
+func appendCompactValue(res []byte, value string) []byte {
+	number, err := strconv.ParseInt(value, numberBase, 64)
+	if err == nil && strconv.FormatInt(number, numberBase) == value {
+		res = binary.AppendUvarint(res, compactIntValue)
+
+		return binary.AppendVarint(res, number)
+	}
+
+	res = binary.AppendUvarint(res, uint64(len(value))+1)
+
+	return append(res, value...)
+}
+
+func (c *CompactCodec) decode(q *Query, data string) error {
+	raw, err := base64.RawURLEncoding.Strict().DecodeString(strings.TrimPrefix(data, CompactPrefix))
+	if err != nil {
+		return errors.Wrap(ErrInvalidValue, "compact query is not base64url")
+	}
+
+	if len(raw) == 0 || raw[0] != compactVersion {
+		return errors.Wrap(ErrInvalidValue, "compact query has unknown version")
+	}
+
+	reader := compactReader{data: raw[1:]}
+	q.Params = map[string][]string{}
+	q.Command = ""
+
+	for first := true; len(reader.data) > 0; first = false {
+		key, err := c.readKey(&reader)
+		if err != nil {
+			return err
+		}
+
+		count, err := reader.uvarint()
+		if err != nil {
+			return err
+		}
+
+		if count > uint64(len(reader.data)) {
+			return errors.Wrapf(ErrInvalidValue, "compact query has %d values, data is too short", count)
+		}
+
+		values := q.Params[key]
+
+		for range count {
+			value, err := reader.value()
+			if err != nil {
+				return err
+			}
+
+			values = append(values, value)
+		}
+
+		q.Params[key] = values
+
+		if first {
+			q.Command = key
+		}
+	}
+
+	if c.Encode(q) != data {
+		return errors.Wrap(ErrInvalidValue, "compact query is not in canonical form")
+	}
+
+	return nil
+}
+
+func (c *CompactCodec) readKey(reader *compactReader) (string, error) {
+	header, err := reader.uvarint()
+	if err != nil {
+		return "", err
+	}
+
+	if header&compactAliasFlag == 0 {
+		return reader.string(header >> 1)
+	}
+
+	alias := header >> 1
+	if alias >= uint64(len(c.keys)) {
+		return "", errors.Wrapf(ErrInvalidValue, "unknown key alias %d", alias)
+	}
+
+	return c.keys[alias], nil
+}
+
+type compactReader struct {
+	data []byte
+}
+
+func (r *compactReader) uvarint() (uint64, error) {
+	res, size := binary.Uvarint(r.data)
+	if size <= 0 {
+		return 0, errors.Wrap(ErrInvalidValue, "compact query has invalid varint")
+	}
+
+	r.data = r.data[size:]
+
+	return res, nil
+}
+
+func (r *compactReader) string(size uint64) (string, error) {
+	if size > uint64(len(r.data)) {
+		return "", errors.Wrapf(ErrInvalidValue, "compact query has %d bytes string, data is too short", size)
+	}
+
+	res := string(r.data[:size])
+	r.data = r.data[size:]
+
+	return res, nil
+}
+
+func (r *compactReader) value() (string, error) {
+	header, err := r.uvarint()
+	if err != nil {
+		return "", err
+	}
+
+	if header != compactIntValue {
+		return r.string(header - 1)
+	}
+
+	res, size := binary.Varint(r.data)
+	if size <= 0 {
+		return "", errors.Wrap(ErrInvalidValue, "compact query has invalid varint")
+	}
+
+	r.data = r.data[size:]
+
+	return strconv.FormatInt(res, numberBase), nil
+}
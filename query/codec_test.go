@@ -0,0 +1,109 @@
+package query
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactCodec(t *testing.T) {
+	codec := NewCompactCodec(WithKeyAliases("article", "id", "page"))
+	payload := regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	testCases := []struct {
+		name  string
+		input *Query
+	}{
+		{
+			name:  "aliases",
+			input: Command("article").WithParamInt64("id", 1234567).WithParamInt64("page", -3),
+		},
+		{
+			name:  "plain keys",
+			input: Command("search").WithParam("q", "hello world, \"quoted\"").WithParamEmpty("all"),
+		},
+		{
+			name:  "non canonical integers",
+			input: Command("menu").WithParam("n", "007").WithParam("n", "+1").WithParam("n", ""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded := codec.Encode(tc.input)
+			require.True(t, IsCompact(encoded))
+			require.Regexp(t, payload, encoded)
+
+			tc.input.WithParamEmpty(tc.input.Command)
+			require.Equal(t, tc.input, codec.Decode(encoded))
+		})
+	}
+
+	q := Command("article").WithParamInt64("id", 1234567).WithParamInt64("page", 3)
+	require.Less(t, len(codec.Encode(q)), len(q.Encode()))
+}
+
+func TestCompactCodec_autoDetect(t *testing.T) {
+	codec := NewCompactCodec(WithKeyAliases("article"))
+
+	require.Equal(t, Decode("article id=1"), codec.Decode("article id=1"))
+
+	plain := NewCompactCodec().Encode(Command("menu").WithParamInt64("page", 2))
+	require.Equal(t, Decode("menu page=2"), Decode(plain))
+	require.Equal(t, Decode("menu page=2"), codec.Decode(plain))
+
+	aliased := codec.Encode(Command("article"))
+	require.Equal(t, Command("article").WithParamEmpty("article"), codec.Decode(aliased))
+	require.Equal(t, Command(aliased).WithParamEmpty(aliased), Decode(aliased))
+
+	testCases := []string{
+		"-",
+		"-menu",
+		"-AgAA",
+		"-5",
+		"- item",
+		plain + "A",
+		plain[:len(plain)-1],
+		"-" + plain[2:],
+	}
+
+	for _, input := range testCases {
+		t.Run(input, func(t *testing.T) {
+			require.Equal(t, Decode(input), codec.Decode(input))
+			require.Equal(t, input, Decode(input).Encode())
+		})
+	}
+}
+
+func TestCompactCodec_EncodeDeepLink(t *testing.T) {
+	codec := NewCompactCodec()
+
+	res, err := codec.EncodeDeepLink(Command("ref").WithParamInt64("u", 42))
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(res), MaxCallbackDataSize)
+
+	_, err = codec.EncodeDeepLink(Command("ref").WithParam("text", string(make([]byte, MaxCallbackDataSize))))
+	require.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestCompactCodec_DecodeCompact(t *testing.T) {
+	codec := NewCompactCodec(WithKeyAliases("article"))
+	aliased := codec.Encode(Command("article").WithParamInt64("id", 1))
+	plain := NewCompactCodec().Encode(Command("menu").WithParamInt64("page", 2))
+
+	res, err := codec.DecodeCompact(aliased)
+	require.NoError(t, err)
+	require.Equal(t, "article id=1", res.Encode())
+
+	res, err = DecodeCompact(plain)
+	require.NoError(t, err)
+	require.Equal(t, "menu page=2", res.Encode())
+
+	for _, input := range []string{"", "-", "-c b a", "menu page=2", plain + "A", aliased} {
+		t.Run(input, func(t *testing.T) {
+			_, err := DecodeCompact(input)
+			require.ErrorIs(t, err, ErrInvalidValue)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package query
+
+import "errors"
+
+var (
+	ErrInvalidValue     = errors.New("invalid value")
+	ErrUnsupportedType  = errors.New("unsupported type")
+	ErrTooLong          = errors.New("too long")
+	ErrExpired          = errors.New("expired")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrMissing          = errors.New("missing")
+)
@@ -0,0 +1,458 @@
+package query
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	tagName        = "query"
+	tagOmitEmpty   = "omitempty"
+	tagCommand     = "command"
+	boolTrue       = "1"
+	boolFalse      = "0"
+	bitSizeFloat32 = 32
+	bitSizeFloat64 = 64
+	numberBase     = 10
+)
+
+//nolint:gochecknoglobals
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	fieldsCache  sync.Map // map[reflect.Type][]fieldInfo
+)
+
+type fieldInfo struct {
+	index     []int
+	name      string
+	key       string
+	omitEmpty bool
+	command   bool
+}
+
+// Marshal encodes the struct into the query using `query` field tags.
+//
+// Tag format is `query:"key,options"`:
+//   - key is the parameter name, field name is used if it is empty;
+//   - "-" skips the field;
+//   - omitempty option skips zero values;
+//   - command option marks the string field holding the Command.
+//
+// Supported field types: string, bool, all int, uint and float types, time.Duration,
+// slices of them (multiple values) and pointers to them (optional, nil is skipped).
+//
+// Example:
+//
+//	type Article struct {
+//		Command string `query:",command"`
+//		ID      int64  `query:"id"`
+//		Page    *int   `query:"p"`
+//	}
+//
+//	q, err := query.Marshal(Article{Command: "article", ID: 1}) // "article id=1"
+func Marshal(v any) (*Query, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, errors.Wrapf(ErrUnsupportedType, "marshal %T: struct expected", v)
+	}
+
+	fields, err := cachedFields(value.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	res := New()
+
+	for _, field := range fields {
+		fieldValue := value.FieldByIndex(field.index)
+
+		if field.command {
+			res.Command = fieldValue.String()
+
+			continue
+		}
+
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		values, ok, err := encodeValue(fieldValue)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "marshal field %s", field.name)
+		}
+
+		if ok {
+			res.Params[field.key] = append(res.Params[field.key], values...)
+		}
+	}
+
+	if res.Command != "" {
+		res.WithParamEmpty(res.Command)
+	}
+
+	return res, nil
+}
+
+// Unmarshal decodes the query into the struct pointed by v using `query` field tags.
+// Fields without parameters in the query are left unchanged. See Marshal for the tag format.
+func Unmarshal(q *Query, v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return errors.Wrapf(ErrUnsupportedType, "unmarshal %T: non-nil pointer expected", v)
+	}
+
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return errors.Wrapf(ErrUnsupportedType, "unmarshal %T: pointer to struct expected", v)
+	}
+
+	fields, err := cachedFields(value.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		fieldValue := value.FieldByIndex(field.index)
+
+		if field.command {
+			fieldValue.SetString(q.Command)
+
+			continue
+		}
+
+		values, ok := q.Params[field.key]
+		if !ok {
+			continue
+		}
+
+		err := decodeValue(fieldValue, values)
+		if err != nil {
+			return errors.WithMessagef(err, "unmarshal field %s (%s)", field.name, field.key)
+		}
+	}
+
+	return nil
+}
+
+func cachedFields(typ reflect.Type) ([]fieldInfo, error) {
+	if cached, ok := fieldsCache.Load(typ); ok {
+		return cached.([]fieldInfo), nil //nolint:forcetypeassert
+	}
+
+	res, err := typeFields(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldsCache.Store(typ, res)
+
+	return res, nil
+}
+
+func typeFields(typ reflect.Type) ([]fieldInfo, error) {
+	var (
+		res     []fieldInfo
+		command bool
+	)
+
+	for _, field := range reflect.VisibleFields(typ) {
+		if field.Anonymous || !field.IsExported() || throughPointer(typ, field.Index) {
+			continue
+		}
+
+		tag := field.Tag.Get(tagName)
+		if tag == "-" {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(tag, ",")
+
+		info := fieldInfo{
+			index: field.Index,
+			name:  field.Name,
+			key:   key,
+		}
+
+		if info.key == "" {
+			info.key = field.Name
+		}
+
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case tagOmitEmpty:
+				info.omitEmpty = true
+			case tagCommand:
+				info.command = true
+			}
+		}
+
+		if info.command {
+			if command {
+				return nil, errors.Wrapf(ErrUnsupportedType, "%s: more than one command field", typ)
+			}
+
+			if field.Type.Kind() != reflect.String {
+				return nil, errors.Wrapf(ErrUnsupportedType, "%s: command field %s must be string", typ, field.Name)
+			}
+
+			command = true
+		}
+
+		res = append(res, info)
+	}
+
+	return res, nil
+}
+
+// throughPointer reports whether the promoted field is reached through an embedded pointer.
+func throughPointer(typ reflect.Type, index []int) bool {
+	for _, idx := range index[:len(index)-1] {
+		typ = typ.Field(idx).Type
+		if typ.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encodeValue returns false for nil pointers.
+func encodeValue(value reflect.Value) ([]string, bool, error) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return nil, false, nil
+		}
+
+		return encodeValue(value.Elem())
+	case reflect.Slice:
+		res := make([]string, 0, value.Len())
+
+		for i := range value.Len() {
+			item, err := encodeScalar(value.Index(i))
+			if err != nil {
+				return nil, false, err
+			}
+
+			res = append(res, item)
+		}
+
+		return res, true, nil
+	default:
+		res, err := encodeScalar(value)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return []string{res}, true, nil
+	}
+}
+
+func encodeScalar(value reflect.Value) (string, error) {
+	if value.Type() == durationType {
+		return time.Duration(value.Int()).String(), nil
+	}
+
+	//nolint:exhaustive
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		if value.Bool() {
+			return boolTrue, nil
+		}
+
+		return boolFalse, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), numberBase), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), numberBase), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(value.Float(), 'g', -1, bitSizeFloat32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, bitSizeFloat64), nil
+	}
+
+	return "", errors.Wrapf(ErrUnsupportedType, "%s", value.Type())
+}
+
+func decodeValue(value reflect.Value, values []string) error {
+	switch value.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(value.Type().Elem())
+
+		err := decodeValue(elem.Elem(), values)
+		if err != nil {
+			return err
+		}
+
+		value.Set(elem)
+
+		return nil
+	case reflect.Slice:
+		res := reflect.MakeSlice(value.Type(), len(values), len(values))
+
+		for i, item := range values {
+			err := decodeScalar(res.Index(i), item)
+			if err != nil {
+				return err
+			}
+		}
+
+		value.Set(res)
+
+		return nil
+	default:
+		if len(values) == 0 {
+			return decodeFlag(value)
+		}
+
+		return decodeScalar(value, values[0])
+	}
+}
+
+// decodeFlag decodes the parameter without value: bool is true, other types are zero.
+func decodeFlag(value reflect.Value) error {
+	if value.Kind() == reflect.Bool {
+		value.SetBool(true)
+
+		return nil
+	}
+
+	return decodeScalar(value, "")
+}
+
+func decodeScalar(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		res, err := parseDuration(raw)
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(int64(res))
+
+		return nil
+	}
+
+	//nolint:exhaustive
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		res, err := parseBool(raw)
+		if err != nil {
+			return err
+		}
+
+		value.SetBool(res)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		res, err := parseInt(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(res)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		res, err := parseUint(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetUint(res)
+	case reflect.Float32, reflect.Float64:
+		res, err := parseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetFloat(res)
+	default:
+		return errors.Wrapf(ErrUnsupportedType, "%s", value.Type())
+	}
+
+	return nil
+}
+
+func parseBool(raw string) (bool, error) {
+	if raw == "" {
+		return true, nil
+	}
+
+	res, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, errors.Wrapf(ErrInvalidValue, "parse %q as bool", raw)
+	}
+
+	return res, nil
+}
+
+func parseInt(raw string, bitSize int) (int64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	res, err := strconv.ParseInt(raw, numberBase, bitSize)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidValue, "parse %q as int%d: %v", raw, bitSize, unwrapNumError(err))
+	}
+
+	return res, nil
+}
+
+func parseUint(raw string, bitSize int) (uint64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	res, err := strconv.ParseUint(raw, numberBase, bitSize)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidValue, "parse %q as uint%d: %v", raw, bitSize, unwrapNumError(err))
+	}
+
+	return res, nil
+}
+
+func parseFloat(raw string, bitSize int) (float64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	res, err := strconv.ParseFloat(raw, bitSize)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidValue, "parse %q as float%d: %v", raw, bitSize, unwrapNumError(err))
+	}
+
+	return res, nil
+}
+
+func parseDuration(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	res, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidValue, "parse %q as duration", raw)
+	}
+
+	return res, nil
+}
+
+// unwrapNumError drops the function name and the input from strconv errors.
+func unwrapNumError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,128 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type marshalTarget struct {
+	Command  string        `query:",command"`
+	ID       int64         `query:"id"`
+	Small    int8          `query:"i8"`
+	Unsigned uint16        `query:"u"`
+	Ratio    float64       `query:"r,omitempty"`
+	Flag     bool          `query:"f"`
+	Name     string        `query:"n,omitempty"`
+	Tags     []string      `query:"t,omitempty"`
+	IDs      []int32       `query:"ids,omitempty"`
+	Timeout  time.Duration `query:"d,omitempty"`
+	Page     *int          `query:"p"`
+	Skipped  string        `query:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	page := 2
+
+	res, err := Marshal(&marshalTarget{
+		Command:  "list",
+		ID:       -5,
+		Small:    3,
+		Unsigned: 7,
+		Flag:     true,
+		IDs:      []int32{1, 2},
+		Timeout:  time.Minute,
+		Page:     &page,
+		Skipped:  "x",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "list d=1m0s f=1 i8=3 id=-5 ids=1,2 p=2 u=7", res.Encode())
+
+	res, err = Marshal(marshalTarget{Command: "list"})
+	require.NoError(t, err)
+	require.Equal(t, "list f=0 i8=0 id=0 u=0", res.Encode())
+
+	_, err = Marshal(1)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestUnmarshal(t *testing.T) {
+	var res marshalTarget
+
+	err := Unmarshal(Decode("list d=1m0s f i8=3 id=-5 ids=1,2 p=2 r=0.5 t=a,b u=7"), &res)
+	require.NoError(t, err)
+
+	page := 2
+
+	require.Equal(t, marshalTarget{
+		Command:  "list",
+		ID:       -5,
+		Small:    3,
+		Unsigned: 7,
+		Ratio:    0.5,
+		Flag:     true,
+		Tags:     []string{"a", "b"},
+		IDs:      []int32{1, 2},
+		Timeout:  time.Minute,
+		Page:     &page,
+	}, res)
+}
+
+func TestUnmarshal_errors(t *testing.T) {
+	testCases := []struct {
+		input string
+		err   string
+	}{
+		{
+			input: "cmd id=abc",
+			err:   `unmarshal field ID (id): parse "abc" as int64: invalid syntax: invalid value`,
+		},
+		{
+			input: "cmd i8=300",
+			err:   `unmarshal field Small (i8): parse "300" as int8: value out of range: invalid value`,
+		},
+		{
+			input: "cmd u=-1",
+			err:   `unmarshal field Unsigned (u): parse "-1" as uint16: invalid syntax: invalid value`,
+		},
+		{
+			input: "cmd f=maybe",
+			err:   `unmarshal field Flag (f): parse "maybe" as bool: invalid value`,
+		},
+		{
+			input: "cmd d=5",
+			err:   `unmarshal field Timeout (d): parse "5" as duration: invalid value`,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.input, func(t *testing.T) {
+			var res marshalTarget
+
+			err := Unmarshal(Decode(tC.input), &res)
+			require.ErrorIs(t, err, ErrInvalidValue)
+			require.EqualError(t, err, tC.err)
+		})
+	}
+}
+
+func TestMarshal_roundTrip(t *testing.T) {
+	page := 0
+	input := marshalTarget{
+		Command: "cmd",
+		ID:      1 << 40,
+		Ratio:   -1.25,
+		Name:    "name",
+		Tags:    []string{"x"},
+		Page:    &page,
+	}
+
+	query, err := Marshal(input)
+	require.NoError(t, err)
+
+	var output marshalTarget
+
+	require.NoError(t, Unmarshal(Decode(query.Encode()), &output))
+	require.Equal(t, input, output)
+}
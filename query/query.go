@@ -31,6 +31,9 @@ func Command(command string) *Query {
 }
 
 // Decode decodes the query string into the Query structure.
+// Keys and values with delimiters are quoted, see JoinKeyValues.
+// Data encoded by CompactCodec without key aliases is detected and decoded too,
+// other texts starting with CompactPrefix are decoded as text.
 //
 // Text examples:
 //
@@ -38,6 +41,7 @@ func Command(command string) *Query {
 //	"menu page=help"
 //	"menu=help"
 //	"article id=1"
+//	`search q="hello world"`
 func Decode(query string) *Query {
 	res := New()
 	res.Decode(query)
@@ -169,12 +173,6 @@ func (q *Query) Encode() string {
 			continue
 		}
 
-		if len(values) == 0 {
-			params = append(params, key)
-
-			continue
-		}
-
 		params = append(params, JoinKeyValues(key, values))
 	}
 
@@ -188,7 +186,15 @@ func (q *Query) Encode() string {
 }
 
 func (q *Query) Decode(query string) {
-	paramsList := strings.Split(query, QueryParamDelimiter)
+	if IsCompact(query) && defaultCompactCodec.decode(q, query) == nil {
+		return
+	}
+
+	q.decodeText(query)
+}
+
+func (q *Query) decodeText(query string) {
+	paramsList := splitUnquoted(query, QueryParamDelimiter[0])
 
 	q.Params = make(map[string][]string, len(paramsList))
 	q.Command, _ = SplitKeyValues(paramsList[0])
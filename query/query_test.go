@@ -17,6 +17,15 @@ func TestDecode(t *testing.T) {
 				Params: map[string][]string{},
 			},
 		},
+		{
+			input: "-",
+			output: &Query{
+				Command: "-",
+				Params: map[string][]string{
+					"-": nil,
+				},
+			},
+		},
 		{
 			input: "/add",
 			output: &Query{
@@ -59,6 +68,40 @@ func TestDecode(t *testing.T) {
 				},
 			},
 		},
+		{
+			input: `search q="hello world","a,b" title="say \"hi\"" path=C:\dir`,
+			output: &Query{
+				Command: "search",
+				Params: map[string][]string{
+					"search": nil,
+					"q":      {"hello world", "a,b"},
+					"title":  {`say "hi"`},
+					"path":   {`C:\dir`},
+				},
+			},
+		},
+		{
+			input: `legacy a="b d=e"f x="y"z`,
+			output: &Query{
+				Command: "legacy",
+				Params: map[string][]string{
+					"legacy": nil,
+					"a":      {`"b`},
+					"d":      {`e"f`},
+					"x":      {`"y"z`},
+				},
+			},
+		},
+		{
+			input: `"my cmd"="a=b" ""`,
+			output: &Query{
+				Command: "my cmd",
+				Params: map[string][]string{
+					"my cmd": {"a=b"},
+					"":       nil,
+				},
+			},
+		},
 	}
 
 	for _, tC := range testCases {
@@ -117,6 +160,17 @@ func TestQuery_Encode(t *testing.T) {
 			},
 			output: "command=test params with",
 		},
+		{
+			input: Query{
+				Command: "search",
+				Params: map[string][]string{
+					"q":     {"hello world", "a,b", ""},
+					"title": {`say "hi"`, `\`},
+					"x=y":   nil,
+				},
+			},
+			output: `search "x=y" q="hello world","a,b","" title="say \"hi\"",\`,
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.output, func(t *testing.T) {
@@ -174,3 +228,26 @@ func TestQuery_getters(t *testing.T) {
 		require.Equal(t, int64(42), value)
 	}
 }
+
+func FuzzQuery_EncodeDecode(f *testing.F) {
+	f.Add("search", "q", "hello world", "a,b")
+	f.Add("menu", "page", `"quoted"`, `back\slash`)
+	f.Add("", "", "", "=")
+	f.Add(`"`, `\"`, `" "`, `,"`)
+
+	f.Fuzz(func(t *testing.T, command string, key string, first string, second string) {
+		if command == "" || key == command {
+			t.Skip()
+		}
+
+		input := Command(command).
+			WithParam(key, first).
+			WithParam(key, second).
+			WithParamEmpty("flag")
+
+		res := Decode(input.Encode())
+
+		input.WithParamEmpty(command)
+		require.Equal(t, input, res)
+	})
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureDelimiter separates the signature from the signed data.
+	SignatureDelimiter = " !"
+
+	defaultSignatureSize = 8
+	signatureVersion     = 1
+	expiryDelimiter      = "."
+	expiryBase           = 36
+)
+
+type SignerOption func(*Signer)
+
+// WithSignatureSize sets the length of the truncated HMAC in bytes. Default is 8.
+// Each byte takes 4/3 characters of callback_data.
+func WithSignatureSize(size int) SignerOption {
+	return func(s *Signer) {
+		s.size = min(max(size, 1), sha256.Size)
+	}
+}
+
+// WithSignatureTTL sets the lifetime of signatures created by Sign. Default is 0, signatures never expire.
+func WithSignatureTTL(ttl time.Duration) SignerOption {
+	return func(s *Signer) {
+		s.ttl = ttl
+	}
+}
+
+// Signer protects data from tampering with a truncated HMAC-SHA256 signature.
+//
+// Signed data has the form "<data> !<signature>" or "<data> !<expiry>.<signature>",
+// where expiry is a unix time in base 36. Default signature takes 13 bytes, expiry takes 7 more.
+// The MAC covers a fixed header with the format version and the length-prefixed expiry before the data,
+// so the data of one signature can never be read as the expiry of another.
+type Signer struct {
+	key  []byte
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+func NewSigner(key []byte, opts ...SignerOption) *Signer {
+	res := &Signer{
+		key:  key,
+		size: defaultSignatureSize,
+		now:  time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// Sign appends the signature to the data.
+// With WithSignatureTTL option the signature expires after the TTL.
+func (s *Signer) Sign(data string) string {
+	if s.ttl > 0 {
+		return s.SignUntil(data, s.now().Add(s.ttl))
+	}
+
+	return data + SignatureDelimiter + s.signature(data, "")
+}
+
+// SignUntil appends the signature that expires at the time.
+func (s *Signer) SignUntil(data string, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), expiryBase)
+
+	return data + SignatureDelimiter + expiry + expiryDelimiter + s.signature(data, expiry)
+}
+
+// SignQuery encodes and signs the query.
+// Returns ErrTooLong if the signed query does not fit into callback_data.
+func (s *Signer) SignQuery(q *Query) (string, error) {
+	res := s.Sign(q.Encode())
+	if len(res) > MaxCallbackDataSize {
+		return "", errors.Wrapf(ErrTooLong, "signed callback data is %d bytes, limit is %d", len(res), MaxCallbackDataSize)
+	}
+
+	return res, nil
+}
+
+// Verify checks the signature and returns the data without it.
+// Returns ErrInvalidSignature if the signature is missing or does not match,
+// ErrExpired if the signature is expired. Data is returned in both cases,
+// so the update can still be matched and rejected by the handler.
+// The data is returned unchanged if it has no well-formed signature.
+func (s *Signer) Verify(data string) (string, error) {
+	res, expiry, signature, ok := s.parse(data)
+	if !ok {
+		return data, errors.Wrap(ErrInvalidSignature, "missing signature")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.signature(res, expiry))) {
+		return res, errors.WithStack(ErrInvalidSignature)
+	}
+
+	if expiry == "" {
+		return res, nil
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, expiryBase, 64)
+	if err != nil {
+		return res, errors.Wrapf(ErrInvalidSignature, "expiry %q", expiry)
+	}
+
+	if s.now().Unix() > expiresAt {
+		return res, errors.Wrapf(ErrExpired, "signature expired at %s", time.Unix(expiresAt, 0).UTC())
+	}
+
+	return res, nil
+}
+
+// parse splits the data into the signed data, expiry and signature.
+// Returns false unless the suffix has exactly the form produced by Sign,
+// so texts that merely contain the delimiter are not cut.
+func (s *Signer) parse(data string) (string, string, string, bool) {
+	idx := strings.LastIndex(data, SignatureDelimiter)
+	if idx < 0 {
+		return "", "", "", false
+	}
+
+	res, signature := data[:idx], data[idx+len(SignatureDelimiter):]
+
+	expiry, signature, hasExpiry := strings.Cut(signature, expiryDelimiter)
+	if !hasExpiry {
+		signature, expiry = expiry, ""
+	} else if expiry == "" || strings.Trim(expiry, "0123456789abcdefghijklmnopqrstuvwxyz") != "" {
+		return "", "", "", false
+	}
+
+	if len(signature) != base64.RawURLEncoding.EncodedLen(s.size) {
+		return "", "", "", false
+	}
+
+	_, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return "", "", "", false
+	}
+
+	return res, expiry, signature, true
+}
+
+func (s *Signer) signature(data string, expiry string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte{signatureVersion, byte(len(expiry))})
+	mac.Write([]byte(expiry))
+	mac.Write([]byte(data))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:s.size])
+}
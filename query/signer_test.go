@@ -0,0 +1,103 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+
+	signed := signer.Sign("article id=1")
+	require.Len(t, signed, len("article id=1")+13)
+
+	data, err := signer.Verify(signed)
+	require.NoError(t, err)
+	require.Equal(t, "article id=1", data)
+
+	testCases := []struct {
+		name  string
+		input string
+		data  string
+	}{
+		{name: "unsigned", input: "article id=1", data: "article id=1"},
+		{name: "tampered data", input: "article id=999" + signed[len("article id=1"):], data: "article id=999"},
+		{name: "tampered signature", input: signed[:len(signed)-1] + "A", data: "article id=1"},
+		{name: "other key", input: NewSigner([]byte("other")).Sign("article id=1"), data: "article id=1"},
+		{name: "empty signature", input: "article id=1 !", data: "article id=1 !"},
+		{name: "delimiter in text", input: `search q="wow !nice"`, data: `search q="wow !nice"`},
+		{name: "short signature", input: signed[:len(signed)-1], data: signed[:len(signed)-1]},
+		{name: "invalid signature chars", input: "article id=1 !" + strings.Repeat("*", 11), data: "article id=1 !" + strings.Repeat("*", 11)},
+		{name: "invalid expiry chars", input: "article id=1 !A." + signed[len("article id=1 !"):], data: "article id=1 !A." + signed[len("article id=1 !"):]},
+		{name: "empty expiry", input: "article id=1 !." + signed[len("article id=1 !"):], data: "article id=1 !." + signed[len("article id=1 !"):]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := signer.Verify(tc.input)
+			require.ErrorIs(t, err, ErrInvalidSignature)
+			require.Equal(t, tc.data, data)
+		})
+	}
+}
+
+func TestSigner_domainSeparation(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	signer := NewSigner([]byte("secret"))
+	signer.now = func() time.Time { return now }
+
+	// Data signed without expiry must not verify as other data with an expiry.
+	signed := signer.Sign("a\x00zzzzzz")
+	signature := signed[len("a\x00zzzzzz !"):]
+
+	data, err := signer.Verify("a !zzzzzz." + signature)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	require.Equal(t, "a", data)
+
+	expiring := signer.SignUntil("a", now.Add(time.Hour))
+	expiry, signature, _ := strings.Cut(expiring[len("a !"):], ".")
+
+	_, err = signer.Verify("a" + "\x00" + expiry + " !" + signature)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestSigner_expiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	signer := NewSigner([]byte("secret"), WithSignatureTTL(time.Hour))
+	signer.now = func() time.Time { return now }
+
+	signed := signer.Sign("menu")
+
+	data, err := signer.Verify(signed)
+	require.NoError(t, err)
+	require.Equal(t, "menu", data)
+
+	extended := signer.SignUntil("menu", now.Add(2*time.Hour))
+	expiry, _, _ := strings.Cut(extended[len("menu !"):], ".")
+	_, signature, _ := strings.Cut(signed[len("menu !"):], ".")
+
+	_, err = signer.Verify("menu !" + expiry + "." + signature)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	now = now.Add(2 * time.Hour)
+
+	data, err = signer.Verify(signed)
+	require.ErrorIs(t, err, ErrExpired)
+	require.Equal(t, "menu", data)
+}
+
+func TestSigner_SignQuery(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+
+	res, err := signer.SignQuery(Command("article").WithParamInt64("id", 1))
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(res), MaxCallbackDataSize)
+
+	_, err = signer.SignQuery(Command("article").WithParam("text", string(make([]byte, MaxCallbackDataSize-10))))
+	require.ErrorIs(t, err, ErrTooLong)
+}
@@ -0,0 +1,204 @@
+package query
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const bitSize64 = 64
+
+// Typed getters return ErrMissing if the key is absent and ErrInvalidValue if the value is malformed.
+// A key without values is a flag: true for bool, zero for numbers, empty slice for slices.
+
+func (q *Query) WithParamBool(key string, value bool) *Query {
+	if value {
+		return q.WithParam(key, boolTrue)
+	}
+
+	return q.WithParam(key, boolFalse)
+}
+
+func (q *Query) GetBool(key string) (bool, error) {
+	return getValue(q, key, parseBool)
+}
+
+func (q *Query) GetBoolInto(key string, into *bool) error {
+	return getInto(q, key, into, parseBool)
+}
+
+func (q *Query) GetBoolSlice(key string) ([]bool, error) {
+	return getSlice(q, key, parseBool)
+}
+
+func (q *Query) WithParamUint64(key string, value uint64) *Query {
+	return q.WithParam(key, strconv.FormatUint(value, numberBase))
+}
+
+func (q *Query) GetUint64(key string) (uint64, error) {
+	return getValue(q, key, parseUint64)
+}
+
+func (q *Query) GetUint64Into(key string, into *uint64) error {
+	return getInto(q, key, into, parseUint64)
+}
+
+func (q *Query) GetUint64Slice(key string) ([]uint64, error) {
+	return getSlice(q, key, parseUint64)
+}
+
+func (q *Query) WithParamFloat64(key string, value float64) *Query {
+	return q.WithParam(key, strconv.FormatFloat(value, 'g', -1, bitSizeFloat64))
+}
+
+func (q *Query) GetFloat64(key string) (float64, error) {
+	return getValue(q, key, parseFloat64)
+}
+
+func (q *Query) GetFloat64Into(key string, into *float64) error {
+	return getInto(q, key, into, parseFloat64)
+}
+
+func (q *Query) GetFloat64Slice(key string) ([]float64, error) {
+	return getSlice(q, key, parseFloat64)
+}
+
+// WithParamTime adds the time as Unix seconds.
+func (q *Query) WithParamTime(key string, value time.Time) *Query {
+	return q.WithParam(key, strconv.FormatInt(value.Unix(), numberBase))
+}
+
+// GetTime returns the time from Unix seconds.
+func (q *Query) GetTime(key string) (time.Time, error) {
+	return getValue(q, key, parseUnixTime)
+}
+
+func (q *Query) GetTimeInto(key string, into *time.Time) error {
+	return getInto(q, key, into, parseUnixTime)
+}
+
+func (q *Query) GetTimeSlice(key string) ([]time.Time, error) {
+	return getSlice(q, key, parseUnixTime)
+}
+
+// WithParamDuration adds the duration in time.Duration.String format, e.g. "1h30m".
+func (q *Query) WithParamDuration(key string, value time.Duration) *Query {
+	return q.WithParam(key, value.String())
+}
+
+func (q *Query) GetDuration(key string) (time.Duration, error) {
+	return getValue(q, key, parseDuration)
+}
+
+func (q *Query) GetDurationInto(key string, into *time.Duration) error {
+	return getInto(q, key, into, parseDuration)
+}
+
+func (q *Query) GetDurationSlice(key string) ([]time.Duration, error) {
+	return getSlice(q, key, parseDuration)
+}
+
+// WithParamEnum adds the string-backed enum value. It is a typed shortcut for WithParam.
+func WithParamEnum[T ~string](q *Query, key string, value T) *Query {
+	return q.WithParam(key, string(value))
+}
+
+// GetEnum returns the value if it is one of the allowed values, otherwise ErrInvalidValue.
+//
+// Example:
+//
+//	type Sort string
+//
+//	sort, err := query.GetEnum(q, "sort", SortByDate, SortByName)
+func GetEnum[T ~string](q *Query, key string, allowed ...T) (T, error) {
+	return getValue(q, key, parseEnum(allowed))
+}
+
+func GetEnumInto[T ~string](q *Query, key string, into *T, allowed ...T) error {
+	return getInto(q, key, into, parseEnum(allowed))
+}
+
+func GetEnumSlice[T ~string](q *Query, key string, allowed ...T) ([]T, error) {
+	return getSlice(q, key, parseEnum(allowed))
+}
+
+func getValue[T any](q *Query, key string, parse func(string) (T, error)) (T, error) {
+	var zero T
+
+	values, ok := q.Params[key]
+	if !ok {
+		return zero, errors.Wrapf(ErrMissing, "param %q", key)
+	}
+
+	var raw string
+	if len(values) > 0 {
+		raw = values[0]
+	}
+
+	res, err := parse(raw)
+	if err != nil {
+		return zero, errors.WithMessagef(err, "param %q", key)
+	}
+
+	return res, nil
+}
+
+func getInto[T any](q *Query, key string, into *T, parse func(string) (T, error)) error {
+	res, err := getValue(q, key, parse)
+	if err != nil {
+		return err
+	}
+
+	*into = res
+
+	return nil
+}
+
+func getSlice[T any](q *Query, key string, parse func(string) (T, error)) ([]T, error) {
+	values, ok := q.Params[key]
+	if !ok {
+		return nil, errors.Wrapf(ErrMissing, "param %q", key)
+	}
+
+	res := make([]T, 0, len(values))
+
+	for _, raw := range values {
+		value, err := parse(raw)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "param %q", key)
+		}
+
+		res = append(res, value)
+	}
+
+	return res, nil
+}
+
+func parseUint64(raw string) (uint64, error) {
+	return parseUint(raw, bitSize64)
+}
+
+func parseFloat64(raw string) (float64, error) {
+	return parseFloat(raw, bitSizeFloat64)
+}
+
+func parseUnixTime(raw string) (time.Time, error) {
+	res, err := parseInt(raw, bitSize64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(res, 0), nil
+}
+
+func parseEnum[T ~string](allowed []T) func(string) (T, error) {
+	return func(raw string) (T, error) {
+		if !slices.Contains(allowed, T(raw)) {
+			return "", errors.Wrapf(ErrInvalidValue, "%q is not one of %q", raw, allowed)
+		}
+
+		return T(raw), nil
+	}
+}
@@ -0,0 +1,89 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testSort string
+
+const (
+	testSortDate testSort = "date"
+	testSortName testSort = "name"
+)
+
+func TestQuery_typedRoundTrip(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	q := Decode(WithParamEnum(
+		Command("cmd").
+			WithParamBool("b", true).
+			WithParamBool("b", false).
+			WithParamUint64("u", 18446744073709551615).
+			WithParamFloat64("f", 1.5).
+			WithParamTime("t", now).
+			WithParamDuration("d", 90*time.Minute),
+		"s", testSortName,
+	).Encode())
+
+	b, err := q.GetBoolSlice("b")
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false}, b)
+
+	var u uint64
+	require.NoError(t, q.GetUint64Into("u", &u))
+	require.Equal(t, uint64(18446744073709551615), u)
+
+	f, err := q.GetFloat64("f")
+	require.NoError(t, err)
+	require.InDelta(t, 1.5, f, 0)
+
+	ts, err := q.GetTime("t")
+	require.NoError(t, err)
+	require.True(t, now.Equal(ts))
+
+	d, err := q.GetDuration("d")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Minute, d)
+
+	s, err := GetEnum(q, "s", testSortDate, testSortName)
+	require.NoError(t, err)
+	require.Equal(t, testSortName, s)
+}
+
+func TestQuery_typedErrors(t *testing.T) {
+	q := Decode("cmd flag b=yes u=-1 f=x t=1.5 d=5 s=size")
+
+	testCases := []struct {
+		name string
+		get  func(key string) error
+	}{
+		{name: "b", get: func(key string) error { _, err := q.GetBool(key); return err }},
+		{name: "u", get: func(key string) error { _, err := q.GetUint64(key); return err }},
+		{name: "f", get: func(key string) error { _, err := q.GetFloat64(key); return err }},
+		{name: "t", get: func(key string) error { _, err := q.GetTime(key); return err }},
+		{name: "d", get: func(key string) error { _, err := q.GetDuration(key); return err }},
+		{name: "s", get: func(key string) error { _, err := GetEnum(q, key, testSortDate, testSortName); return err }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.get(tc.name)
+			require.ErrorIs(t, err, ErrInvalidValue)
+			require.NotErrorIs(t, err, ErrMissing)
+
+			err = tc.get("missing")
+			require.ErrorIs(t, err, ErrMissing)
+			require.NotErrorIs(t, err, ErrInvalidValue)
+		})
+	}
+
+	b, err := q.GetBool("flag")
+	require.NoError(t, err)
+	require.True(t, b)
+
+	_, err = GetEnum(q, "flag", testSortDate)
+	require.ErrorIs(t, err, ErrInvalidValue)
+}
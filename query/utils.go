@@ -2,24 +2,152 @@ package query
 
 import "strings"
 
+const (
+	quoteChar  = '"'
+	escapeChar = '\\'
+
+	// specialChars are the delimiters that require quoting.
+	specialChars = QueryParamDelimiter + QueryValueDelimiter + QuerySliceDelimiter
+)
+
+// JoinKeyValues encodes the key and values into a query parameter.
+// The key and values with delimiters are quoted, quotes and backslashes inside are escaped:
+//
+//	JoinKeyValues("q", []string{"hello world", "a,b"}) // q="hello world","a,b"
 func JoinKeyValues(key string, values []string) string {
 	if len(values) == 0 {
-		return key
+		return Quote(key)
+	}
+
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = Quote(value)
 	}
 
-	return key + QueryValueDelimiter + strings.Join(values, QuerySliceDelimiter)
+	return Quote(key) + QueryValueDelimiter + strings.Join(quoted, QuerySliceDelimiter)
 }
 
-//nolint:nonamedreturns,mnd
+// SplitKeyValues decodes the query parameter encoded by JoinKeyValues.
+// Unquoted parts are taken as is, so the data encoded without quoting is decoded the same way as before.
+//
+//nolint:nonamedreturns
 func SplitKeyValues(keyValues string) (key string, values []string) {
-	parts := strings.SplitN(keyValues, QueryValueDelimiter, 2)
-	if len(parts) >= 1 {
-		key = parts[0]
-	}
+	rawKey, rawValues, ok := cutUnquoted(keyValues, QueryValueDelimiter[0])
+	key = Unquote(rawKey)
 
-	if len(parts) >= 2 {
-		values = strings.Split(parts[1], QuerySliceDelimiter)
+	if ok {
+		values = splitUnquoted(rawValues, QuerySliceDelimiter[0])
+		for i, value := range values {
+			values[i] = Unquote(value)
+		}
 	}
 
 	return key, values
 }
+
+// Quote returns the string quoted if it is empty or contains delimiters, otherwise the string as is.
+func Quote(str string) string {
+	if str != "" && !strings.ContainsAny(str, specialChars) && str[0] != quoteChar {
+		return str
+	}
+
+	var res strings.Builder
+
+	res.Grow(len(str) + 2) //nolint:mnd
+	res.WriteByte(quoteChar)
+
+	for i := range len(str) {
+		if str[i] == quoteChar || str[i] == escapeChar {
+			res.WriteByte(escapeChar)
+		}
+
+		res.WriteByte(str[i])
+	}
+
+	res.WriteByte(quoteChar)
+
+	return res.String()
+}
+
+// Unquote reverses Quote. Strings that are not properly quoted are returned as is.
+func Unquote(str string) string {
+	if quotedEnd(str, 0) != len(str)-1 {
+		return str
+	}
+
+	var res strings.Builder
+
+	res.Grow(len(str))
+
+	for i := 1; i < len(str)-1; i++ {
+		if str[i] == escapeChar {
+			i++
+		}
+
+		res.WriteByte(str[i])
+	}
+
+	return res.String()
+}
+
+// quotedEnd returns the index of the quote closing the quoted part started at the index.
+// Returns -1 if there is no quoted part at the index.
+// The closing quote must be followed by a delimiter or the end of the string.
+func quotedEnd(str string, start int) int {
+	if start >= len(str) || str[start] != quoteChar {
+		return -1
+	}
+
+	for i := start + 1; i < len(str); i++ {
+		switch str[i] {
+		case escapeChar:
+			i++
+		case quoteChar:
+			if i+1 < len(str) && !strings.ContainsRune(specialChars, rune(str[i+1])) {
+				return -1
+			}
+
+			return i
+		}
+	}
+
+	return -1
+}
+
+// splitUnquoted splits the string by the delimiter outside of quoted parts.
+func splitUnquoted(str string, delimiter byte) []string {
+	var res []string
+
+	for {
+		before, after, ok := cutUnquoted(str, delimiter)
+
+		res = append(res, before)
+		if !ok {
+			return res
+		}
+
+		str = after
+	}
+}
+
+// cutUnquoted cuts the string around the first delimiter outside of quoted parts.
+// Quoted part can start only at the beginning of the string or after a delimiter.
+//
+//nolint:nonamedreturns
+func cutUnquoted(str string, delimiter byte) (before string, after string, found bool) {
+	for i := 0; i < len(str); i++ {
+		if i == 0 || strings.ContainsRune(specialChars, rune(str[i-1])) {
+			if end := quotedEnd(str, i); end >= 0 {
+				i = end
+
+				continue
+			}
+		}
+
+		if str[i] == delimiter {
+			return str[:i], str[i+1:], true
+		}
+	}
+
+	return str, "", false
+}
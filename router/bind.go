@@ -0,0 +1,61 @@
+package router
+
+import (
+	"github.com/opoccomaxao/tg-instrumentation/query"
+	"github.com/pkg/errors"
+)
+
+// BindErrorHandler handles errors of decoding the query in Bind handlers.
+type BindErrorHandler func(ctx *Context, err error)
+
+// DefaultBindErrorHandler collects the error with Context.Error and aborts the chain.
+func DefaultBindErrorHandler(ctx *Context, err error) {
+	ctx.Error(err)
+	ctx.Abort()
+}
+
+// Bind returns a handler that decodes Context.Query into T and calls fn.
+// T must be a struct with `query` field tags, see query.Unmarshal.
+// It works for text commands, callbacks and inline queries alike.
+//
+// Decoding errors are passed to the handler set by WithBindErrorHandler,
+// fn is not called in this case.
+//
+// Example:
+//
+//	type ArticleArgs struct {
+//		ID int64 `query:"id"`
+//	}
+//
+//	r.Callback("article", router.Bind(func(ctx *router.Context, args ArticleArgs) {
+//		...
+//	}))
+func Bind[T any](fn func(ctx *Context, args T)) Handler {
+	return func(ctx *Context) {
+		var args T
+
+		err := ctx.Bind(&args)
+		if err != nil {
+			ctx.router.bindError(ctx, err)
+
+			return
+		}
+
+		fn(ctx, args)
+	}
+}
+
+// Bind decodes Context.Query into the struct pointed by v. See query.Unmarshal.
+func (c *Context) Bind(v any) error {
+	q := c.Query()
+	if q == nil {
+		return errors.Wrapf(ErrFailed, "bind: %s update has no query", c.Kind())
+	}
+
+	err := query.Unmarshal(q, v)
+	if err != nil {
+		return errors.WithMessage(err, "bind")
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/opoccomaxao/tg-instrumentation/query"
+	"github.com/stretchr/testify/require"
+)
+
+type bindArgs struct {
+	ID   int64  `query:"id"`
+	Name string `query:"name"`
+}
+
+func TestBind(t *testing.T) {
+	testCases := []struct {
+		name     string
+		update   *apimodels.Update
+		called   bool
+		expected bindArgs
+		bindErr  error
+	}{
+		{
+			name:     "callback",
+			update:   callbackUpdate("article id=5 name=news"),
+			called:   true,
+			expected: bindArgs{ID: 5, Name: "news"},
+		},
+		{
+			name:     "text command",
+			update:   textUpdate("/article id=7"),
+			called:   true,
+			expected: bindArgs{ID: 7},
+		},
+		{
+			name:    "malformed value",
+			update:  callbackUpdate("article id=abc"),
+			bindErr: query.ErrInvalidValue,
+		},
+		{
+			name:    "no query",
+			update:  &apimodels.Update{ChatMember: &models.ChatMemberUpdated{}},
+			bindErr: ErrFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				called  bool
+				args    bindArgs
+				bindErr error
+			)
+
+			handler := Bind(func(ctx *Context, res bindArgs) {
+				ctx.Accept()
+
+				called = true
+				args = res
+			})
+
+			router := New(WithBindErrorHandler(func(ctx *Context, err error) {
+				ctx.Accept()
+
+				bindErr = err
+			}))
+			router.Callback("article", handler)
+			router.Text("/article", handler)
+			router.ChatMember(handler)
+
+			accepted, err := router.Handle(context.Background(), tc.update)
+			require.NoError(t, err)
+			require.True(t, accepted)
+			require.Equal(t, tc.called, called)
+			require.Equal(t, tc.expected, args)
+
+			if tc.bindErr == nil {
+				require.NoError(t, bindErr)
+			} else {
+				require.ErrorIs(t, bindErr, tc.bindErr)
+			}
+		})
+	}
+}
+
+func TestBind_defaultErrorHandler(t *testing.T) {
+	called := false
+
+	router := New()
+	router.Callback("article", Bind(func(*Context, bindArgs) {
+		called = true
+	}), func(*Context) {
+		require.Fail(t, "chain is not aborted")
+	})
+
+	accepted, err := router.Handle(context.Background(), callbackUpdate("article id=abc"))
+	require.ErrorIs(t, err, query.ErrInvalidValue)
+	require.False(t, accepted)
+	require.False(t, called)
+}
@@ -0,0 +1,69 @@
+package router
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	commandPrefix   = "/"
+	usernameSep     = "@"
+	ignoredPattern  = usernameSep
+	commandEndChars = " \t\n"
+)
+
+// FetchBotUsername loads the username of the bot with getMe.
+// Commands addressed to the bot as "/cmd@username" are matched as "/cmd",
+// commands addressed to other bots are ignored. See WithBotUsername.
+//
+// WARNING: this method must be called in the initialization phase.
+func (r *Router) FetchBotUsername(ctx context.Context) error {
+	if r.client == nil {
+		return errors.Wrap(ErrFailed, "client is not set. use router.New() with router.WithClient() option")
+	}
+
+	user, err := r.client.GetMe(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	r.botUsername = user.Username
+
+	return nil
+}
+
+// BotUsername returns the username of the bot set by WithBotUsername or FetchBotUsername.
+func (r *Router) BotUsername() string {
+	return r.botUsername
+}
+
+// stripBotUsername removes the bot username from the command in the message text.
+// Returns false if the command is addressed to another bot.
+func (r *Router) stripBotUsername(ctx *Context) bool {
+	if r.botUsername == "" || ctx.text == nil || !isMessageKind(ctx.kind) || !strings.HasPrefix(*ctx.text, commandPrefix) {
+		return true
+	}
+
+	text := *ctx.text
+
+	end := strings.IndexAny(text, commandEndChars)
+	if end == -1 {
+		end = len(text)
+	}
+
+	command, username, ok := strings.Cut(text[:end], usernameSep)
+	if !ok {
+		return true
+	}
+
+	if !strings.EqualFold(username, r.botUsername) {
+		return false
+	}
+
+	text = command + text[end:]
+	ctx.text = &text
+
+	return true
+}
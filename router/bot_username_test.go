@@ -0,0 +1,85 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_botUsername(t *testing.T) {
+	testCases := []struct {
+		name    string
+		update  *apimodels.Update
+		pattern string
+		handled string
+	}{
+		{name: "own bot", update: textUpdate("/cmd@MyBot arg"), pattern: "/cmd", handled: "/cmd arg"},
+		{name: "case insensitive", update: textUpdate("/cmd@mybot"), pattern: "/cmd", handled: "/cmd"},
+		{name: "without username", update: textUpdate("/cmd"), pattern: "/cmd", handled: "/cmd"},
+		{name: "other bot", update: textUpdate("/cmd@OtherBot arg"), pattern: ignoredPattern},
+		{name: "username in args", update: textUpdate("/cmd to@OtherBot"), pattern: "/cmd", handled: "/cmd to@OtherBot"},
+		{name: "not a command", update: textUpdate("cmd@OtherBot"), pattern: "cmd@OtherBot", handled: "cmd@OtherBot"},
+		{name: "callback", update: callbackUpdate("/cmd@OtherBot"), pattern: "/cmd@OtherBot", handled: "/cmd@OtherBot"},
+		{
+			name: "inline",
+			update: &apimodels.Update{
+				InlineQuery: &models.InlineQuery{ID: "1", Query: "/cmd@OtherBot", From: &models.User{ID: 1}},
+			},
+			pattern: "/cmd@OtherBot",
+			handled: "/cmd@OtherBot",
+		},
+		{
+			name: "edited message",
+			update: &apimodels.Update{
+				EditedMessage: &models.Message{Text: "/cmd@OtherBot", Chat: models.Chat{ID: 1}},
+			},
+			pattern: ignoredPattern,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pattern, handled string
+
+			handler := func(ctx *Context) {
+				handled, _ = ctx.Text()
+
+				ctx.Accept()
+			}
+
+			router := New(WithBotUsername("@MyBot"))
+			router.Use(func(ctx *Context) {
+				pattern = ctx.Pattern()
+
+				ctx.Next()
+			})
+			router.Text("/cmd", handler)
+			router.Text("cmd@OtherBot", handler)
+			router.Callback("/cmd@OtherBot", handler)
+			router.Inline("/cmd@OtherBot", handler)
+
+			accepted, err := router.Handle(context.Background(), tc.update)
+			require.NoError(t, err)
+			require.True(t, accepted)
+			require.Equal(t, tc.pattern, pattern)
+			require.Equal(t, tc.handled, handled)
+		})
+	}
+}
+
+func TestRouter_FetchBotUsername(t *testing.T) {
+	client, _ := newTestBot(t, func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Bot","username":"MyBot"}}`))
+	})
+
+	router := New(WithClient(client))
+	require.NoError(t, router.FetchBotUsername(context.Background()))
+	require.Equal(t, "MyBot", router.BotUsername())
+
+	require.ErrorIs(t, New().FetchBotUsername(context.Background()), ErrFailed)
+}
@@ -18,11 +18,13 @@ type Context struct {
 	CtxTemp
 
 	router *Router
+	keys   map[string]any // kept between updates to reuse the memory, cleared in reset.
 }
 
 type CtxTemp struct {
 	ctx      context.Context //nolint:containedctx
 	update   *models.Update
+	kind     UpdateKind
 	text     *string
 	pattern  string
 	raw      *bytes.Buffer
@@ -30,12 +32,26 @@ type CtxTemp struct {
 	errors   []error
 	index    int
 	accepted bool
+	notFound bool
+
+	paramNames []string
+	params     []string
+
+	signatureErr error
+
+	state       string
+	stateLoaded bool
+	session     *Session
+
+	apiObservers []APICallObserver
 }
 
 func (c *Context) reset() {
 	c.CtxTemp = CtxTemp{
 		index: -1,
 	}
+
+	clear(c.keys)
 }
 
 func (c *Context) Context() context.Context {
@@ -88,6 +104,11 @@ func (c *Context) IsAccepted() bool {
 	return c.accepted
 }
 
+// IsNotFound returns true if no route matched the update and the not found handler is used.
+func (c *Context) IsNotFound() bool {
+	return c.notFound
+}
+
 func (c *Context) Error(err error) {
 	c.errors = append(c.errors, err)
 }
@@ -1,11 +1,23 @@
 package router
 
 import (
+	"context"
+	"time"
+
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/pkg/errors"
 )
 
+// APICallObserver is called after each Bot API request made through the Context.
+type APICallObserver func(ctx *Context, method string, duration time.Duration, err error)
+
+// OnAPICall registers the observer for Bot API requests made while handling the current update.
+// It is intended to be called from middlewares.
+func (c *Context) OnAPICall(observer APICallObserver) {
+	c.apiObservers = append(c.apiObservers, observer)
+}
+
 func (c *Context) getClient() (*bot.Bot, error) {
 	if c.router.client == nil {
 		return nil, errors.Wrap(ErrFailed, "client is not set. use router.New() with router.WithClient() option")
@@ -14,20 +26,69 @@ func (c *Context) getClient() (*bot.Bot, error) {
 	return c.router.client, nil
 }
 
+// callAPI performs the Bot API request and notifies observers.
+// Requests sending messages to the chat wait for the flood limiter, nil chatID skips waiting.
+// With flood limiter set, requests rejected with retry_after are retried.
+func callAPI[T any](
+	c *Context,
+	method string,
+	chatID any,
+	call func(ctx context.Context, client *bot.Bot) (T, error),
+) (T, error) {
+	var zero T
+
+	client, err := c.getClient()
+	if err != nil {
+		return zero, err
+	}
+
+	limiter := c.router.flood
+
+	for attempt := 0; ; attempt++ {
+		if limiter != nil && chatID != nil {
+			err = limiter.Wait(c.ctx, chatID)
+			if err != nil {
+				return zero, err
+			}
+		}
+
+		start := time.Now()
+		res, err := call(c.ctx, client)
+		duration := time.Since(start)
+
+		for _, observer := range c.apiObservers {
+			observer(c, method, duration, err)
+		}
+
+		if err == nil {
+			return res, nil
+		}
+
+		if limiter == nil {
+			return res, errors.WithStack(err)
+		}
+
+		delay, ok := limiter.retryDelay(err, attempt)
+		if !ok || !sleepContext(c.ctx, delay) {
+			return res, errors.WithStack(err)
+		}
+	}
+}
+
 // AnswerCallbackQuery https://core.telegram.org/bots/api#answercallbackquery
 func (c *Context) AnswerCallbackQuery(
 	params *bot.AnswerCallbackQueryParams,
 ) (bool, error) {
-	client, err := c.getClient()
+	res, err := callAPI(
+		c, "answerCallbackQuery", nil,
+		func(ctx context.Context, client *bot.Bot) (bool, error) {
+			return client.AnswerCallbackQuery(ctx, params)
+		},
+	)
 	if err != nil {
 		return false, err
 	}
 
-	res, err := client.AnswerCallbackQuery(c.ctx, params)
-	if err != nil {
-		return false, errors.WithStack(err)
-	}
-
 	c.Accept()
 
 	return res, nil
@@ -37,16 +98,16 @@ func (c *Context) AnswerCallbackQuery(
 func (c *Context) SendMessage(
 	params *bot.SendMessageParams,
 ) (*models.Message, error) {
-	client, err := c.getClient()
+	res, err := callAPI(
+		c, "sendMessage", params.ChatID,
+		func(ctx context.Context, client *bot.Bot) (*models.Message, error) {
+			return client.SendMessage(ctx, params)
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := client.SendMessage(c.ctx, params)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
 	c.Accept()
 
 	return res, nil
@@ -56,16 +117,16 @@ func (c *Context) SendMessage(
 func (c *Context) SendPhoto(
 	params *bot.SendPhotoParams,
 ) (*models.Message, error) {
-	client, err := c.getClient()
+	res, err := callAPI(
+		c, "sendPhoto", params.ChatID,
+		func(ctx context.Context, client *bot.Bot) (*models.Message, error) {
+			return client.SendPhoto(ctx, params)
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := client.SendPhoto(c.ctx, params)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
 	c.Accept()
 
 	return res, nil
@@ -75,16 +136,16 @@ func (c *Context) SendPhoto(
 func (c *Context) EditMessageText(
 	params *bot.EditMessageTextParams,
 ) (*models.Message, error) {
-	client, err := c.getClient()
+	res, err := callAPI(
+		c, "editMessageText", params.ChatID,
+		func(ctx context.Context, client *bot.Bot) (*models.Message, error) {
+			return client.EditMessageText(ctx, params)
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := client.EditMessageText(c.ctx, params)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
 	return res, nil
 }
 
@@ -92,16 +153,16 @@ func (c *Context) EditMessageText(
 func (c *Context) EditMessageMedia(
 	params *bot.EditMessageMediaParams,
 ) (*models.Message, error) {
-	client, err := c.getClient()
+	res, err := callAPI(
+		c, "editMessageMedia", params.ChatID,
+		func(ctx context.Context, client *bot.Bot) (*models.Message, error) {
+			return client.EditMessageMedia(ctx, params)
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := client.EditMessageMedia(c.ctx, params)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
 	return res, nil
 }
 
@@ -109,16 +170,16 @@ func (c *Context) EditMessageMedia(
 func (c *Context) SetMessageReaction(
 	params *bot.SetMessageReactionParams,
 ) (bool, error) {
-	client, err := c.getClient()
+	res, err := callAPI(
+		c, "setMessageReaction", nil,
+		func(ctx context.Context, client *bot.Bot) (bool, error) {
+			return client.SetMessageReaction(ctx, params)
+		},
+	)
 	if err != nil {
 		return false, err
 	}
 
-	res, err := client.SetMessageReaction(c.ctx, params)
-	if err != nil {
-		return false, errors.WithStack(err)
-	}
-
 	c.Accept()
 
 	return res, nil
@@ -128,16 +189,16 @@ func (c *Context) SetMessageReaction(
 func (c *Context) DeleteMessage(
 	params *bot.DeleteMessageParams,
 ) (bool, error) {
-	client, err := c.getClient()
+	res, err := callAPI(
+		c, "deleteMessage", nil,
+		func(ctx context.Context, client *bot.Bot) (bool, error) {
+			return client.DeleteMessage(ctx, params)
+		},
+	)
 	if err != nil {
 		return false, err
 	}
 
-	res, err := client.DeleteMessage(c.ctx, params)
-	if err != nil {
-		return false, errors.WithStack(err)
-	}
-
 	c.Accept()
 
 	return res, nil
@@ -0,0 +1,53 @@
+package router
+
+// Set stores the value for the current update.
+// Use it to pass data from middlewares to handlers.
+// Values are removed when the update is handled.
+func (c *Context) Set(key string, value any) {
+	if c.keys == nil {
+		c.keys = map[string]any{}
+	}
+
+	c.keys[key] = value
+}
+
+// Get returns the value stored with Set.
+func (c *Context) Get(key string) (any, bool) {
+	value, ok := c.keys[key]
+
+	return value, ok
+}
+
+// Delete removes the value stored with Set.
+func (c *Context) Delete(key string) {
+	delete(c.keys, key)
+}
+
+// ContextValue returns the typed value stored with Context.Set.
+// Returns false if the key is missing or the value has another type.
+//
+// Example:
+//
+//	user, ok := router.ContextValue[*User](ctx, "user")
+func ContextValue[T any](ctx *Context, key string) (T, bool) {
+	value, ok := ctx.Get(key)
+	if !ok {
+		var zero T
+
+		return zero, false
+	}
+
+	res, ok := value.(T)
+
+	return res, ok
+}
+
+// ContextValueOr returns the typed value stored with Context.Set or the fallback.
+func ContextValueOr[T any](ctx *Context, key string, fallback T) T {
+	res, ok := ContextValue[T](ctx, key)
+	if !ok {
+		return fallback
+	}
+
+	return res
+}
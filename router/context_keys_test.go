@@ -0,0 +1,65 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_keysReset(t *testing.T) {
+	var (
+		value any
+		found bool
+	)
+
+	router := New()
+	router.Text("/set", func(ctx *Context) {
+		ctx.Accept()
+		ctx.Set("user", "alice")
+	})
+	router.Text("/get", func(ctx *Context) {
+		ctx.Accept()
+
+		value, found = ctx.Get("user")
+	})
+
+	for _, text := range []string{"/set", "/get"} {
+		_, err := router.Handle(context.Background(), textUpdate(text))
+		require.NoError(t, err)
+	}
+
+	require.False(t, found)
+	require.Nil(t, value)
+}
+
+func TestContextValue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    any
+		set      bool
+		expected int
+		ok       bool
+		or       int
+	}{
+		{name: "typed", value: 5, set: true, expected: 5, ok: true, or: 5},
+		{name: "type mismatch", value: "5", set: true, or: -1},
+		{name: "nil value", value: nil, set: true, or: -1},
+		{name: "missing", or: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctx Context
+
+			if tc.set {
+				ctx.Set("key", tc.value)
+			}
+
+			res, ok := ContextValue[int](&ctx, "key")
+			require.Equal(t, tc.expected, res)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.or, ContextValueOr(&ctx, "key", -1))
+		})
+	}
+}
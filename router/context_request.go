@@ -1,12 +1,17 @@
 package router
 
-import "github.com/opoccomaxao/tg-instrumentation/query"
+import (
+	"github.com/opoccomaxao/tg-instrumentation/query"
+	"github.com/pkg/errors"
+)
 
 // Query returns the query from the text.
-// For text message, it returns the query from the text.
+// For text message, channel post, business message and their edited versions,
+// it returns the query from the text.
 // For callback query, it returns the query from the data.
-// For inline query, it returns the query from the query.
-// For other types of messages, it returns nil.
+// For inline query and chosen inline result, it returns the query from the query.
+// For shipping and pre-checkout queries, it returns the query from the invoice payload.
+// For other types of updates, it returns nil.
 func (c *Context) Query() *query.Query {
 	if c.text == nil {
 		return nil
@@ -14,3 +19,84 @@ func (c *Context) Query() *query.Query {
 
 	return query.Decode(*c.text)
 }
+
+// Text returns the text used for matching: message text, callback data, inline query, etc.
+// Callback data is already prepared for matching: tokens are expanded, signature is stripped
+// and compact form is converted to text. The bot username is stripped from commands.
+// Texts typed by users are kept as is, normalization of WithNormalizer is used only for matching.
+// Returns false for updates without text.
+func (c *Context) Text() (string, bool) {
+	if c.text == nil {
+		return "", false
+	}
+
+	return *c.text, true
+}
+
+// OriginalText returns the text from the update before any preparation for matching.
+// Returns false for updates without text.
+func (c *Context) OriginalText() (string, bool) {
+	text := updateText(c.update)
+	if text == nil {
+		return "", false
+	}
+
+	return *text, true
+}
+
+// EncodeCallback encodes the query into callback data with the codec set by WithCallbackCodec.
+// With WithCallbackSigner option the data is signed.
+// With WithCallbackEncoder option long queries are replaced with tokens,
+// otherwise query.ErrTooLong is returned for them.
+func (c *Context) EncodeCallback(q *query.Query) (string, error) {
+	data := c.router.callbackCodec.Encode(q)
+
+	if c.router.callbackSigner != nil {
+		data = c.router.callbackSigner.Sign(data)
+	}
+
+	if c.router.callbackEncoder != nil {
+		//nolint:wrapcheck
+		return c.router.callbackEncoder.EncodeString(c.ctx, data)
+	}
+
+	if len(data) > query.MaxCallbackDataSize {
+		return "", errors.Wrapf(query.ErrTooLong, "callback data is %d bytes, limit is %d", len(data), query.MaxCallbackDataSize)
+	}
+
+	return data, nil
+}
+
+// SignatureError returns the error of callback data verification.
+// It is nil for valid signatures and updates other than callback queries.
+// See WithCallbackSigner and SignedCallbacks.
+func (c *Context) SignatureError() error {
+	return c.signatureErr
+}
+
+// Param returns the value captured by the named param of the pattern, e.g. "id" for "/article :id",
+// or by the named group of the regular expression.
+// Returns empty string if the pattern has no such param.
+func (c *Context) Param(name string) string {
+	for i, paramName := range c.paramNames {
+		if paramName == name && name != "" {
+			return c.params[i]
+		}
+	}
+
+	return ""
+}
+
+// Wildcards returns the values captured by `*` of the pattern
+// or by unnamed groups of the regular expression in order.
+func (c *Context) Wildcards() []string {
+	var res []string
+
+	for i, paramName := range c.paramNames {
+		if paramName == "" {
+			res = append(res, c.params[i])
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,53 @@
+package router
+
+import "github.com/pkg/errors"
+
+// State returns the conversation state or empty string.
+// The state is loaded once per update, load errors are collected with Context.Error.
+func (c *Context) State() string {
+	if c.stateLoaded {
+		return c.state
+	}
+
+	c.stateLoaded = true
+
+	key, ok := c.router.stateKey(c.update)
+	if !ok {
+		return ""
+	}
+
+	state, err := c.router.stateStore.GetState(c.ctx, key)
+	if err != nil {
+		c.Error(errors.WithMessage(err, "get state"))
+
+		return ""
+	}
+
+	c.state = state
+
+	return state
+}
+
+// SetState saves the conversation state. Next updates of the conversation
+// are routed to the routes registered with Router.State.
+func (c *Context) SetState(state string) error {
+	key, ok := c.router.stateKey(c.update)
+	if !ok {
+		return errors.Wrap(ErrFailed, "update has no conversation")
+	}
+
+	err := c.router.stateStore.SetState(c.ctx, key, state)
+	if err != nil {
+		return errors.WithMessage(err, "set state")
+	}
+
+	c.state = state
+	c.stateLoaded = true
+
+	return nil
+}
+
+// ClearState resets the conversation to the common routes.
+func (c *Context) ClearState() error {
+	return c.SetState("")
+}
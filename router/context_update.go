@@ -0,0 +1,105 @@
+package router
+
+import "github.com/go-telegram/bot/models"
+
+// Kind returns the kind of the update.
+func (c *Context) Kind() UpdateKind {
+	return c.kind
+}
+
+// ChatID returns the ID of the chat where the update happened.
+func (c *Context) ChatID() (int64, bool) {
+	return updateChatID(c.update)
+}
+
+// UserID returns the ID of the user who initiated the update.
+func (c *Context) UserID() (int64, bool) {
+	return updateUserID(c.update)
+}
+
+// Message returns the new incoming message or nil.
+func (c *Context) Message() *models.Message {
+	return c.update.Message
+}
+
+// EditedMessage returns the edited message or nil.
+func (c *Context) EditedMessage() *models.Message {
+	return c.update.EditedMessage
+}
+
+// ChannelPost returns the new channel post or nil.
+func (c *Context) ChannelPost() *models.Message {
+	return c.update.ChannelPost
+}
+
+// EditedChannelPost returns the edited channel post or nil.
+func (c *Context) EditedChannelPost() *models.Message {
+	return c.update.EditedChannelPost
+}
+
+// BusinessMessage returns the new message from a connected business account or nil.
+func (c *Context) BusinessMessage() *models.Message {
+	return c.update.BusinessMessage
+}
+
+// EditedBusinessMessage returns the edited message from a connected business account or nil.
+func (c *Context) EditedBusinessMessage() *models.Message {
+	return c.update.EditedBusinessMessage
+}
+
+// AnyMessage returns the message of any message-like update:
+// message, channel post, business message, their edited versions,
+// or the accessible message of the callback query.
+func (c *Context) AnyMessage() *models.Message {
+	return updateMessage(c.update)
+}
+
+// CallbackQuery returns the callback query or nil.
+func (c *Context) CallbackQuery() *models.CallbackQuery {
+	return c.update.CallbackQuery
+}
+
+// InlineQuery returns the inline query or nil.
+func (c *Context) InlineQuery() *models.InlineQuery {
+	return c.update.InlineQuery
+}
+
+// ChosenInlineResult returns the chosen inline result or nil.
+func (c *Context) ChosenInlineResult() *models.ChosenInlineResult {
+	return c.update.ChosenInlineResult
+}
+
+// ShippingQuery returns the shipping query or nil.
+func (c *Context) ShippingQuery() *models.ShippingQuery {
+	return c.update.ShippingQuery
+}
+
+// PreCheckoutQuery returns the pre-checkout query or nil.
+func (c *Context) PreCheckoutQuery() *models.PreCheckoutQuery {
+	return c.update.PreCheckoutQuery
+}
+
+// PollAnswer returns the poll answer or nil.
+func (c *Context) PollAnswer() *models.PollAnswer {
+	return c.update.PollAnswer
+}
+
+// ChatMember returns the chat member update or nil.
+func (c *Context) ChatMember() *models.ChatMemberUpdated {
+	return c.update.ChatMember
+}
+
+// MyChatMember returns the bot's own chat member update or nil.
+func (c *Context) MyChatMember() *models.ChatMemberUpdated {
+	return c.update.MyChatMember
+}
+
+// ChatJoinRequest returns the chat join request or nil.
+func (c *Context) ChatJoinRequest() *models.ChatJoinRequest {
+	return c.update.ChatJoinRequest
+}
+
+// MessageReaction returns the message reaction update or nil.
+func (c *Context) MessageReaction() *models.MessageReactionUpdated {
+	return c.update.MessageReaction
+}
@@ -0,0 +1,283 @@
+package router
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/pkg/errors"
+)
+
+const defaultDispatchQueueSize = 1024
+
+// DispatchKeyFunc returns the ordering key of the update.
+// Updates with the same key are handled sequentially in the order of arrival.
+// Updates without a key are distributed between workers.
+type DispatchKeyFunc func(update *apimodels.Update) (int64, bool)
+
+type DispatcherOption func(*Dispatcher)
+
+// WithDispatchWorkers sets the number of workers. Default is the number of CPUs.
+func WithDispatchWorkers(workers int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.workers = workers
+	}
+}
+
+// WithDispatchQueueSize sets the total number of updates waiting for a worker, shared by all keys.
+// When the queue is full, Handle blocks until there is space or the context is done.
+func WithDispatchQueueSize(size int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.queueSize = size
+	}
+}
+
+// WithDispatchKey sets the ordering key function. Default is DispatchKeyChat.
+func WithDispatchKey(keyFunc DispatchKeyFunc) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.keyFunc = keyFunc
+	}
+}
+
+// WithDispatchErrorHandler sets the callback for errors returned by the handler.
+func WithDispatchErrorHandler(handler func(update *apimodels.Update, err error)) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.onError = handler
+	}
+}
+
+// DispatchKeyChat orders updates by chat, falling back to user for updates without chat.
+func DispatchKeyChat(update *apimodels.Update) (int64, bool) {
+	if id, ok := updateChatID(update); ok {
+		return id, true
+	}
+
+	return updateUserID(update)
+}
+
+// DispatchKeyUser orders updates by user, falling back to chat for updates without user.
+func DispatchKeyUser(update *apimodels.Update) (int64, bool) {
+	if id, ok := updateUserID(update); ok {
+		return id, true
+	}
+
+	return updateChatID(update)
+}
+
+type dispatchJob struct {
+	ctx    context.Context //nolint:containedctx
+	update *apimodels.Update
+	opts   []ContextOption
+}
+
+// dispatchQueue holds the updates of one key in the order of arrival.
+// Updates without a key get a queue of their own.
+type dispatchQueue struct {
+	key   int64
+	keyed bool
+	jobs  []dispatchJob
+}
+
+// Dispatcher handles updates concurrently in a pool of workers.
+//
+// Updates with the same key (chat or user) are handled one at a time in the order of arrival,
+// while any idle worker picks up the next waiting key, so a slow chat never delays other chats.
+// It implements UpdateHandler and can be used with Poller and Router.WebhookHandler.
+type Dispatcher struct {
+	handler   UpdateHandler
+	workers   int
+	queueSize int
+	keyFunc   DispatchKeyFunc
+	onError   func(update *apimodels.Update, err error)
+	onBlock   func() // called when Handle waits for space in the queue, for tests.
+
+	slots chan struct{} // one per queued update, bounds the total queue size.
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	closed  bool
+	pending map[int64]*dispatchQueue // keys with queued or running updates.
+	ready   []*dispatchQueue         // queues waiting for a worker, in the order of arrival.
+}
+
+// NewDispatcher creates a dispatcher and starts its workers.
+// Call Close to stop it.
+func NewDispatcher(
+	handler UpdateHandler,
+	opts ...DispatcherOption,
+) *Dispatcher {
+	res := &Dispatcher{
+		handler:   handler,
+		workers:   runtime.NumCPU(),
+		queueSize: defaultDispatchQueueSize,
+		keyFunc:   DispatchKeyChat,
+		onError:   func(*apimodels.Update, error) {},
+		onBlock:   func() {},
+		done:      make(chan struct{}),
+		pending:   map[int64]*dispatchQueue{},
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	res.cond = sync.NewCond(&res.mu)
+	res.slots = make(chan struct{}, max(res.queueSize, 1))
+
+	for range max(res.workers, 1) {
+		res.wg.Add(1)
+
+		go res.work()
+	}
+
+	return res
+}
+
+// Handle queues the update and returns immediately.
+// The result is always accepted once the update is queued,
+// handler errors are reported to the handler from WithDispatchErrorHandler.
+//
+// The handler receives a context that is not cancelled together with ctx,
+// because webhook request contexts end before the update is processed.
+func (d *Dispatcher) Handle(
+	ctx context.Context,
+	update *apimodels.Update,
+	opts ...ContextOption,
+) (bool, error) {
+	err := d.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		<-d.slots
+
+		return false, errors.Wrap(ErrFailed, "dispatcher is closed")
+	}
+
+	job := dispatchJob{
+		ctx:    context.WithoutCancel(ctx),
+		update: update,
+		opts:   opts,
+	}
+
+	key, ok := d.keyFunc(update)
+	if !ok {
+		d.ready = append(d.ready, &dispatchQueue{jobs: []dispatchJob{job}})
+		d.cond.Signal()
+
+		return true, nil
+	}
+
+	// Queue of the key is already waiting or running, its worker picks the update up in order.
+	if queue := d.pending[key]; queue != nil {
+		queue.jobs = append(queue.jobs, job)
+
+		return true, nil
+	}
+
+	queue := &dispatchQueue{key: key, keyed: true, jobs: []dispatchJob{job}}
+	d.pending[key] = queue
+	d.ready = append(d.ready, queue)
+	d.cond.Signal()
+
+	return true, nil
+}
+
+// acquire takes a slot in the queue, waiting until there is space, ctx is done or the dispatcher is closed.
+func (d *Dispatcher) acquire(ctx context.Context) error {
+	select {
+	case d.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	d.onBlock()
+
+	select {
+	case d.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	case <-d.done:
+		return errors.Wrap(ErrFailed, "dispatcher is closed")
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for {
+		for len(d.ready) == 0 && !d.closed {
+			d.cond.Wait()
+		}
+
+		if len(d.ready) == 0 {
+			return
+		}
+
+		queue := d.ready[0]
+		d.ready[0] = nil
+		d.ready = d.ready[1:]
+
+		job := queue.jobs[0]
+		queue.jobs[0] = dispatchJob{}
+		queue.jobs = queue.jobs[1:]
+
+		<-d.slots
+
+		d.mu.Unlock()
+
+		_, err := d.handler.Handle(job.ctx, job.update, job.opts...)
+		if err != nil {
+			d.onError(job.update, err)
+		}
+
+		d.mu.Lock()
+
+		// The key goes to the end of the line, so busy keys do not starve others.
+		if len(queue.jobs) > 0 {
+			d.ready = append(d.ready, queue)
+		} else if queue.keyed {
+			delete(d.pending, queue.key)
+		}
+	}
+}
+
+// Close stops accepting updates and waits until queued updates are handled.
+// Handle calls blocked on a full queue return an error.
+// Returns the context error if ctx is done before the queue is drained.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+
+	if !d.closed {
+		d.closed = true
+		close(d.done)
+		d.cond.Broadcast()
+	}
+
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
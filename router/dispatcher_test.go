@@ -0,0 +1,270 @@
+package router
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func chatUpdate(id int64, chatID int64) *apimodels.Update {
+	return &apimodels.Update{
+		ID: id,
+		Message: &models.Message{
+			Chat: models.Chat{ID: chatID},
+		},
+	}
+}
+
+// blockingHandler records handled updates and blocks the updates of blocked chats until released.
+type blockingHandler struct {
+	started chan int64
+	release chan struct{}
+	blocked map[int64]bool // chats to block, all chats if nil.
+
+	mu      sync.Mutex
+	handled []int64
+}
+
+func newBlockingHandler(chats ...int64) *blockingHandler {
+	res := &blockingHandler{
+		started: make(chan int64, 16),
+		release: make(chan struct{}),
+	}
+
+	if len(chats) > 0 {
+		res.blocked = map[int64]bool{}
+		for _, chat := range chats {
+			res.blocked[chat] = true
+		}
+	}
+
+	return res
+}
+
+func (h *blockingHandler) Handle(
+	_ context.Context,
+	update *apimodels.Update,
+	_ ...ContextOption,
+) (bool, error) {
+	h.started <- update.ID
+
+	if h.blocked == nil || h.blocked[update.Message.Chat.ID] {
+		<-h.release
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.handled = append(h.handled, update.ID)
+
+	return true, nil
+}
+
+func (h *blockingHandler) result() []int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.handled
+}
+
+// newBlockedDispatcher returns the dispatcher signalling to the channel when Handle waits for space.
+func newBlockedDispatcher(handler UpdateHandler, opts ...DispatcherOption) (*Dispatcher, chan struct{}) {
+	blocked := make(chan struct{}, 1)
+
+	dispatcher := NewDispatcher(handler, append(opts, func(d *Dispatcher) {
+		d.onBlock = func() { blocked <- struct{}{} }
+	})...)
+
+	return dispatcher, blocked
+}
+
+func cancelledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	return ctx
+}
+
+func TestDispatcher_order(t *testing.T) {
+	const (
+		chats   = 8
+		updates = 100
+	)
+
+	var (
+		mu      sync.Mutex
+		handled = map[int64][]int64{}
+	)
+
+	dispatcher := NewDispatcher(updateHandlerFunc(func(_ context.Context, update *apimodels.Update) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handled[update.Message.Chat.ID] = append(handled[update.Message.Chat.ID], update.ID)
+
+		return true, nil
+	}), WithDispatchWorkers(4), WithDispatchQueueSize(4))
+
+	expected := map[int64][]int64{}
+
+	for id := range int64(updates) {
+		chatID := id % chats
+		expected[chatID] = append(expected[chatID], id)
+
+		accepted, err := dispatcher.Handle(context.Background(), chatUpdate(id, chatID))
+		require.NoError(t, err)
+		require.True(t, accepted)
+	}
+
+	require.NoError(t, dispatcher.Close(context.Background()))
+	require.Equal(t, expected, handled)
+}
+
+func TestDispatcher_slowChat(t *testing.T) {
+	handler := newBlockingHandler(1)
+	dispatcher := NewDispatcher(handler, WithDispatchWorkers(2))
+
+	_, err := dispatcher.Handle(context.Background(), chatUpdate(1, 1))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), <-handler.started)
+
+	// Chats 3 and 5 would share a worker with chat 1 if keys were pinned to workers.
+	_, err = dispatcher.Handle(context.Background(), chatUpdate(2, 1))
+	require.NoError(t, err)
+
+	for id := int64(3); id <= 5; id += 2 {
+		_, err = dispatcher.Handle(context.Background(), chatUpdate(id, id))
+		require.NoError(t, err)
+		require.Equal(t, id, <-handler.started)
+	}
+
+	close(handler.release)
+	require.NoError(t, dispatcher.Close(context.Background()))
+	require.ElementsMatch(t, []int64{1, 2, 3, 5}, handler.result())
+}
+
+func TestDispatcher_queueFull(t *testing.T) {
+	handler := newBlockingHandler()
+	dispatcher, blocked := newBlockedDispatcher(handler, WithDispatchWorkers(4), WithDispatchQueueSize(2))
+
+	// Every worker is busy.
+	for id := range int64(4) {
+		_, err := dispatcher.Handle(context.Background(), chatUpdate(id, id))
+		require.NoError(t, err)
+		<-handler.started
+	}
+
+	// The queue size is shared by all keys, not split between workers.
+	for id := int64(4); id < 6; id++ {
+		_, err := dispatcher.Handle(context.Background(), chatUpdate(id, id))
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-blocked
+		cancel()
+	}()
+
+	accepted, err := dispatcher.Handle(ctx, chatUpdate(6, 6))
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, accepted)
+
+	close(handler.release)
+	require.NoError(t, dispatcher.Close(context.Background()))
+	require.ElementsMatch(t, []int64{0, 1, 2, 3, 4, 5}, handler.result())
+}
+
+func TestDispatcher_closeBlockedHandle(t *testing.T) {
+	handler := newBlockingHandler()
+	dispatcher, blocked := newBlockedDispatcher(handler, WithDispatchWorkers(1), WithDispatchQueueSize(1))
+
+	_, err := dispatcher.Handle(context.Background(), chatUpdate(1, 1))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), <-handler.started)
+
+	_, err = dispatcher.Handle(context.Background(), chatUpdate(2, 1))
+	require.NoError(t, err)
+
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := dispatcher.Handle(context.Background(), chatUpdate(3, 1))
+		result <- err
+	}()
+
+	<-blocked
+
+	// Close neither waits for the blocked Handle nor ignores its own context.
+	require.ErrorIs(t, dispatcher.Close(cancelledContext()), context.Canceled)
+	require.ErrorIs(t, <-result, ErrFailed)
+
+	close(handler.release)
+	require.NoError(t, dispatcher.Close(context.Background()))
+	require.Equal(t, []int64{1, 2}, handler.result())
+}
+
+func TestDispatcher_closeDrains(t *testing.T) {
+	type reported struct {
+		id  int64
+		err error
+	}
+
+	var (
+		mu     sync.Mutex
+		errs   []reported
+		failed = errors.New("failed")
+	)
+
+	handler := newBlockingHandler()
+	dispatcher := NewDispatcher(updateHandlerFunc(func(ctx context.Context, update *apimodels.Update) (bool, error) {
+		_, _ = handler.Handle(ctx, update)
+
+		if update.ID%2 == 0 {
+			return true, failed
+		}
+
+		return true, nil
+	}),
+		WithDispatchWorkers(2),
+		WithDispatchQueueSize(8),
+		WithDispatchKey(func(*apimodels.Update) (int64, bool) { return 0, false }),
+		WithDispatchErrorHandler(func(update *apimodels.Update, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			errs = append(errs, reported{id: update.ID, err: err})
+		}),
+	)
+
+	for id := range int64(6) {
+		_, err := dispatcher.Handle(context.Background(), chatUpdate(id, id))
+		require.NoError(t, err)
+	}
+
+	require.ErrorIs(t, dispatcher.Close(cancelledContext()), context.Canceled)
+
+	accepted, err := dispatcher.Handle(context.Background(), chatUpdate(6, 6))
+	require.ErrorIs(t, err, ErrFailed)
+	require.False(t, accepted)
+
+	close(handler.release)
+	require.NoError(t, dispatcher.Close(context.Background()))
+	require.ElementsMatch(t, []int64{0, 1, 2, 3, 4, 5}, handler.result())
+
+	ids := make([]int64, 0, len(errs))
+	for _, r := range errs {
+		require.ErrorIs(t, r.err, failed)
+
+		ids = append(ids, r.id)
+	}
+
+	require.ElementsMatch(t, []int64{0, 2, 4}, ids)
+}
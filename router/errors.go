@@ -3,3 +3,12 @@ package router
 import "errors"
 
 var ErrFailed = errors.New("failed")
+
+// joinErrors returns nil for empty list, otherwise a joined error.
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultFloodGlobalRate  = 30
+	defaultFloodPrivateRate = 1
+	defaultFloodGroupRate   = 20.0 / 60
+	defaultFloodGroupBurst  = 20
+	defaultFloodRetries     = 3
+	floodGlobalKey          = ""
+)
+
+type FloodOption func(*FloodLimiter)
+
+// WithFloodGlobal sets the limit for all chats together. Default is 30 messages per second.
+func WithFloodGlobal(rate float64, burst int) FloodOption {
+	return func(l *FloodLimiter) {
+		l.global = newTokenBuckets(rate, burst, 0)
+	}
+}
+
+// WithFloodPrivate sets the limit per private chat. Default is 1 message per second.
+func WithFloodPrivate(rate float64, burst int) FloodOption {
+	return func(l *FloodLimiter) {
+		l.private = newTokenBuckets(rate, burst, 0)
+	}
+}
+
+// WithFloodGroup sets the limit per group or channel. Default is 20 messages per minute.
+func WithFloodGroup(rate float64, burst int) FloodOption {
+	return func(l *FloodLimiter) {
+		l.group = newTokenBuckets(rate, burst, 0)
+	}
+}
+
+// WithFloodRetries sets how many times a request is retried after 429 Too Many Requests. Default is 3.
+func WithFloodRetries(retries int) FloodOption {
+	return func(l *FloodLimiter) {
+		l.retries = retries
+	}
+}
+
+// FloodLimiter delays outgoing requests to stay within Telegram limits
+// and retries requests rejected with retry_after.
+//
+// It is shared by all contexts of the router, see WithFloodLimiter.
+type FloodLimiter struct {
+	retries int
+	now     func() time.Time
+
+	mu      sync.Mutex
+	global  *tokenBuckets
+	private *tokenBuckets
+	group   *tokenBuckets
+}
+
+func NewFloodLimiter(opts ...FloodOption) *FloodLimiter {
+	res := &FloodLimiter{
+		retries: defaultFloodRetries,
+		now:     time.Now,
+		global:  newTokenBuckets(defaultFloodGlobalRate, defaultFloodGlobalRate, 0),
+		private: newTokenBuckets(defaultFloodPrivateRate, 1, 0),
+		group:   newTokenBuckets(defaultFloodGroupRate, defaultFloodGroupBurst, 0),
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// Wait blocks until a message can be sent to the chat.
+// The chat ID is int64 or "@username" string, the same as in request params.
+// Returns the context error if ctx is done first.
+func (l *FloodLimiter) Wait(ctx context.Context, chatID any) error {
+	wait := l.reserve(chatID)
+	if wait <= 0 {
+		return nil
+	}
+
+	if !sleepContext(ctx, wait) {
+		return errors.WithStack(ctx.Err())
+	}
+
+	return nil
+}
+
+func (l *FloodLimiter) reserve(chatID any) time.Duration {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	chat := l.group
+	if isPrivateChat(chatID) {
+		chat = l.private
+	}
+
+	return max(
+		l.global.reserve(floodGlobalKey, now),
+		chat.reserve(fmt.Sprint(chatID), now),
+	)
+}
+
+// isPrivateChat reports whether the chat ID belongs to a user.
+// User IDs are positive, group and channel IDs are negative or "@username".
+func isPrivateChat(chatID any) bool {
+	switch id := chatID.(type) {
+	case int64:
+		return id > 0
+	case int:
+		return id > 0
+	}
+
+	return false
+}
+
+// retryDelay returns the delay before the next attempt if the error is 429 Too Many Requests.
+func (l *FloodLimiter) retryDelay(err error, attempt int) (time.Duration, bool) {
+	if attempt >= l.retries {
+		return 0, false
+	}
+
+	var tooMany *bot.TooManyRequestsError
+	if !errors.As(err, &tooMany) || tooMany.RetryAfter <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(tooMany.RetryAfter) * time.Second, true
+}
@@ -0,0 +1,212 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPrivateChat(t *testing.T) {
+	testCases := []struct {
+		chatID  any
+		private bool
+	}{
+		{chatID: int64(1), private: true},
+		{chatID: 1, private: true},
+		{chatID: int64(-1), private: false},
+		{chatID: int64(-1001234567890), private: false},
+		{chatID: -1, private: false},
+		{chatID: "@channel", private: false},
+		{chatID: int32(1), private: false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.private, isPrivateChat(tc.chatID), "%T(%v)", tc.chatID, tc.chatID)
+	}
+}
+
+func TestFloodLimiter_reserve(t *testing.T) {
+	type step struct {
+		chatID any
+		wait   time.Duration
+	}
+
+	testCases := []struct {
+		name  string
+		opts  []FloodOption
+		steps []step
+	}{
+		{
+			name: "private",
+			opts: []FloodOption{WithFloodPrivate(1, 1)},
+			steps: []step{
+				{chatID: int64(1), wait: 0},
+				{chatID: int64(1), wait: time.Second},
+				{chatID: int64(2), wait: 0},
+			},
+		},
+		{
+			name: "group",
+			opts: []FloodOption{WithFloodPrivate(1, 1), WithFloodGroup(0.5, 2)},
+			steps: []step{
+				{chatID: int64(-1), wait: 0},
+				{chatID: int64(-1), wait: 0},
+				{chatID: int64(-1), wait: 2 * time.Second},
+				{chatID: "@channel", wait: 0},
+			},
+		},
+		{
+			name: "global",
+			opts: []FloodOption{WithFloodGlobal(1, 2)},
+			steps: []step{
+				{chatID: int64(1), wait: 0},
+				{chatID: int64(2), wait: 0},
+				{chatID: int64(3), wait: time.Second},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newTestClock()
+			limiter := NewFloodLimiter(tc.opts...)
+			limiter.now = clock.Now
+
+			for i, step := range tc.steps {
+				require.Equal(t, step.wait, limiter.reserve(step.chatID), "step %d", i)
+			}
+		})
+	}
+}
+
+func TestFloodLimiter_WaitCancel(t *testing.T) {
+	limiter := NewFloodLimiter(WithFloodPrivate(0.001, 1))
+
+	require.NoError(t, limiter.Wait(context.Background(), int64(1)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	require.ErrorIs(t, limiter.Wait(ctx, int64(1)), context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+// sendMessageServer answers sendMessage with the responses in order, the last one is repeated.
+func sendMessageServer(t *testing.T, responses ...string) (*bot.Bot, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	client, _ := newTestBot(t, func(writer http.ResponseWriter, _ *http.Request) {
+		idx := min(int(calls.Add(1)), len(responses)) - 1
+
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(responses[idx]))
+	})
+
+	return client, &calls
+}
+
+const (
+	tooManyRequestsResponse = `{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":1}}`
+	sendMessageResponse     = `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`
+)
+
+func TestCallAPI_retry(t *testing.T) {
+	testCases := []struct {
+		name      string
+		limiter   *FloodLimiter
+		responses []string
+		calls     int32
+		tooMany   bool
+		minTime   time.Duration
+	}{
+		{
+			name:      "retried",
+			limiter:   NewFloodLimiter(),
+			responses: []string{tooManyRequestsResponse, sendMessageResponse},
+			calls:     2,
+			minTime:   time.Second,
+		},
+		{
+			name:      "retries exhausted",
+			limiter:   NewFloodLimiter(WithFloodRetries(1)),
+			responses: []string{tooManyRequestsResponse},
+			calls:     2,
+			tooMany:   true,
+			minTime:   time.Second,
+		},
+		{
+			name:      "without limiter",
+			responses: []string{tooManyRequestsResponse, sendMessageResponse},
+			calls:     1,
+			tooMany:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, calls := sendMessageServer(t, tc.responses...)
+
+			opts := []Option{WithClient(client)}
+			if tc.limiter != nil {
+				opts = append(opts, WithFloodLimiter(tc.limiter))
+			}
+
+			var sendErr error
+
+			router := New(opts...)
+			router.Text("/cmd", func(ctx *Context) {
+				_, sendErr = ctx.SendMessage(&bot.SendMessageParams{ChatID: int64(1), Text: "hi"})
+			})
+
+			start := time.Now()
+
+			_, err := router.Handle(context.Background(), textUpdate("/cmd"))
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, time.Since(start), tc.minTime)
+			require.Equal(t, tc.calls, calls.Load())
+
+			if !tc.tooMany {
+				require.NoError(t, sendErr)
+
+				return
+			}
+
+			var tooMany *bot.TooManyRequestsError
+			require.ErrorAs(t, sendErr, &tooMany)
+			require.Equal(t, 1, tooMany.RetryAfter)
+		})
+	}
+}
+
+func TestCallAPI_retryCancel(t *testing.T) {
+	client, calls := sendMessageServer(t, tooManyRequestsResponse)
+
+	var sendErr error
+
+	router := New(WithClient(client), WithFloodLimiter(NewFloodLimiter()))
+	router.Text("/cmd", func(ctx *Context) {
+		_, sendErr = ctx.SendMessage(&bot.SendMessageParams{ChatID: int64(1), Text: "hi"})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err := router.Handle(ctx, textUpdate("/cmd"))
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), time.Second, "retry must not wait after cancellation")
+	require.Equal(t, int32(1), calls.Load())
+
+	var tooMany *bot.TooManyRequestsError
+	require.ErrorAs(t, sendErr, &tooMany)
+}
@@ -0,0 +1,272 @@
+package router
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/opoccomaxao/tg-instrumentation/texts"
+)
+
+// Group registers routes with a common pattern prefix and middlewares.
+//
+// Group middlewares run after the global ones from Router.Use
+// and only for routes registered through the group.
+type Group struct {
+	router      *Router
+	table       *routeTable
+	prefix      string
+	middlewares []Handler
+	normalizer  texts.TextReplacer
+}
+
+// Group creates a route group.
+// The prefix is prepended to text, callback and inline patterns registered through the group.
+// Regular expressions are anchored right after the prefix, so a leading `^` is redundant and removed.
+//
+// Example:
+//
+//	admin := r.Group("/admin", adminOnly)
+//	admin.Text("_ban", ban) // handles "/admin_ban"
+func (r *Router) Group(
+	prefix string,
+	middlewares ...Handler,
+) *Group {
+	return &Group{
+		router:      r,
+		table:       r.routes,
+		prefix:      prefix,
+		middlewares: middlewares,
+	}
+}
+
+// Group creates a nested route group.
+// Prefixes are concatenated and middlewares of the parent group run first.
+func (g *Group) Group(
+	prefix string,
+	middlewares ...Handler,
+) *Group {
+	return &Group{
+		router:      g.router,
+		table:       g.table,
+		prefix:      g.prefix + prefix,
+		middlewares: slices.Concat(g.middlewares, middlewares),
+		normalizer:  g.normalizer,
+	}
+}
+
+// Normalized returns a copy of the group matching its routes against the normalized text,
+// e.g. texts.NewNormalizer(texts.NormalizeCase) for case-insensitive commands.
+// Patterns are normalized the same way, regular expressions are kept as is.
+// Captures are taken from the text as typed, Context.Text stays unchanged.
+func (g *Group) Normalized(normalizer texts.TextReplacer) *Group {
+	res := *g
+	res.normalizer = normalizer
+
+	return &res
+}
+
+func (g *Group) pattern(command texts.SimplePattern) texts.SimplePattern {
+	return texts.SimplePattern(g.prefix) + command
+}
+
+// regexp anchors the expression after the literal prefix of the group.
+// The leading `^` of the expression would match only at the start of the text, so it is removed.
+func (g *Group) regexp(expr string) string {
+	if g.prefix == "" {
+		return expr
+	}
+
+	return "^" + regexp.QuoteMeta(g.prefix) + "(?:" + strings.TrimPrefix(expr, "^") + ")"
+}
+
+// addPattern registers the pattern with the group prefix, middlewares and normalization.
+// Patterns of kinds normalized by WithNormalizer are normalized with the router normalizer too.
+func (g *Group) addPattern(
+	kind UpdateKind,
+	command texts.SimplePattern,
+	handler []Handler,
+) {
+	pattern := g.pattern(command)
+
+	if g.router.normalizer != nil && isNormalizedKind(kind) {
+		pattern = pattern.Normalize(g.router.normalizer)
+	}
+
+	if g.normalizer != nil {
+		pattern = pattern.Normalize(g.normalizer)
+	}
+
+	g.table.addPattern(kind, pattern, g.normalizer, g.handlers(handler))
+}
+
+func (g *Group) addRegexp(
+	kind UpdateKind,
+	expr string,
+	handler []Handler,
+) {
+	g.table.addRegexp(kind, g.regexp(expr), g.normalizer, g.handlers(handler))
+}
+
+func (g *Group) handlers(handler []Handler) []Handler {
+	return slices.Concat(g.middlewares, handler)
+}
+
+// Text registers a new text command in the group. See Router.Text.
+func (g *Group) Text(
+	command texts.SimplePattern,
+	handler ...Handler,
+) TextHandler {
+	g.addPattern(KindMessage, command, handler)
+
+	return &rawHandler{
+		pattern:   g.pattern(command).String(),
+		describer: g.router.describer,
+	}
+}
+
+// Callback registers a new callback command in the group. See Router.Callback.
+func (g *Group) Callback(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindCallbackQuery, command, handler)
+}
+
+// Inline registers a new inline command in the group. See Router.Inline.
+func (g *Group) Inline(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindInlineQuery, command, handler)
+}
+
+// TextRegexp registers a new text command matched by the regular expression in the group.
+// See Router.TextRegexp.
+func (g *Group) TextRegexp(
+	expr string,
+	handler ...Handler,
+) {
+	g.addRegexp(KindMessage, expr, handler)
+}
+
+// CallbackRegexp registers a new callback command matched by the regular expression in the group.
+// See Router.CallbackRegexp.
+func (g *Group) CallbackRegexp(
+	expr string,
+	handler ...Handler,
+) {
+	g.addRegexp(KindCallbackQuery, expr, handler)
+}
+
+// InlineRegexp registers a new inline command matched by the regular expression in the group.
+// See Router.InlineRegexp.
+func (g *Group) InlineRegexp(
+	expr string,
+	handler ...Handler,
+) {
+	g.addRegexp(KindInlineQuery, expr, handler)
+}
+
+// Custom registers a new custom command in the group. See Router.Custom.
+// The prefix is not applied to custom matchers.
+func (g *Group) Custom(
+	matcher UpdateMatcher,
+	handler ...Handler,
+) {
+	g.table.custom.AddHandler(matcher, g.handlers(handler)...)
+}
+
+// EditedMessage registers a new edited message handler in the group. See Router.EditedMessage.
+func (g *Group) EditedMessage(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindEditedMessage, command, handler)
+}
+
+// ChannelPost registers a new channel post handler in the group. See Router.ChannelPost.
+func (g *Group) ChannelPost(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindChannelPost, command, handler)
+}
+
+// EditedChannelPost registers a new edited channel post handler in the group. See Router.EditedChannelPost.
+func (g *Group) EditedChannelPost(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindEditedChannelPost, command, handler)
+}
+
+// BusinessMessage registers a new business message handler in the group. See Router.BusinessMessage.
+func (g *Group) BusinessMessage(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindBusinessMessage, command, handler)
+}
+
+// EditedBusinessMessage registers a new edited business message handler in the group. See Router.EditedBusinessMessage.
+func (g *Group) EditedBusinessMessage(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindEditedBusinessMessage, command, handler)
+}
+
+// ChosenInlineResult registers a new chosen inline result handler in the group. See Router.ChosenInlineResult.
+func (g *Group) ChosenInlineResult(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindChosenInlineResult, command, handler)
+}
+
+// ShippingQuery registers a new shipping query handler in the group. See Router.ShippingQuery.
+func (g *Group) ShippingQuery(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindShippingQuery, command, handler)
+}
+
+// PreCheckoutQuery registers a new pre-checkout query handler in the group. See Router.PreCheckoutQuery.
+func (g *Group) PreCheckoutQuery(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	g.addPattern(KindPreCheckoutQuery, command, handler)
+}
+
+// ChatMember registers a chat member handler in the group. See Router.ChatMember.
+func (g *Group) ChatMember(handler ...Handler) {
+	g.table.addKind(KindChatMember, g.handlers(handler))
+}
+
+// MyChatMember registers a my chat member handler in the group. See Router.MyChatMember.
+func (g *Group) MyChatMember(handler ...Handler) {
+	g.table.addKind(KindMyChatMember, g.handlers(handler))
+}
+
+// ChatJoinRequest registers a chat join request handler in the group. See Router.ChatJoinRequest.
+func (g *Group) ChatJoinRequest(handler ...Handler) {
+	g.table.addKind(KindChatJoinRequest, g.handlers(handler))
+}
+
+// PollAnswer registers a poll answer handler in the group. See Router.PollAnswer.
+func (g *Group) PollAnswer(handler ...Handler) {
+	g.table.addKind(KindPollAnswer, g.handlers(handler))
+}
+
+// MessageReaction registers a message reaction handler in the group. See Router.MessageReaction.
+func (g *Group) MessageReaction(handler ...Handler) {
+	g.table.addKind(KindMessageReaction, g.handlers(handler))
+}
+
+// Kind registers a handler for any update kind in the group. See Router.Kind.
+func (g *Group) Kind(kind UpdateKind, handler ...Handler) {
+	g.table.addKind(kind, g.handlers(handler))
+}
@@ -0,0 +1,43 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_TextRegexp(t *testing.T) {
+	testCases := []struct {
+		name      string
+		expr      string
+		text      string
+		found     bool
+		wildcards []string
+	}{
+		{name: "plain", expr: `ban (\d+)`, text: "/adminban 5", found: true, wildcards: []string{"5"}},
+		{name: "anchored", expr: `^ban (\d+)`, text: "/adminban 5", found: true, wildcards: []string{"5"}},
+		{name: "anchored end", expr: `^ban (\d+)$`, text: "/adminban 5 x"},
+		{name: "without prefix", expr: `^ban (\d+)`, text: "ban 5"},
+		{name: "prefix in the middle", expr: `ban (\d+)`, text: "x /adminban 5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var wildcards []string
+
+			router := New()
+			router.NotFound(func(*Context) {})
+			router.Group("/admin").TextRegexp(tc.expr, func(ctx *Context) {
+				wildcards = ctx.Wildcards()
+
+				ctx.Accept()
+			})
+
+			accepted, err := router.Handle(context.Background(), textUpdate(tc.text))
+			require.NoError(t, err)
+			require.Equal(t, tc.found, accepted)
+			require.Equal(t, tc.wildcards, wildcards)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "123:test"
+
+// newTestBot returns the client talking to the fake Bot API server.
+func newTestBot(t *testing.T, handler http.HandlerFunc) (*bot.Bot, *httptest.Server) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := bot.New(testToken, bot.WithSkipGetMe(), bot.WithServerURL(server.URL))
+	require.NoError(t, err)
+
+	return client, server
+}
+
+type updateHandlerFunc func(ctx context.Context, update *apimodels.Update) (bool, error)
+
+func (f updateHandlerFunc) Handle(
+	ctx context.Context,
+	update *apimodels.Update,
+	_ ...ContextOption,
+) (bool, error) {
+	return f(ctx, update)
+}
+
+func textUpdate(text string) *apimodels.Update {
+	return &apimodels.Update{
+		Message: &models.Message{
+			Text: text,
+			Chat: models.Chat{ID: 1},
+			From: &models.User{ID: 1},
+		},
+	}
+}
+
+func callbackUpdate(data string) *apimodels.Update {
+	return &apimodels.Update{
+		CallbackQuery: &models.CallbackQuery{
+			ID:   "1",
+			Data: data,
+			From: models.User{ID: 1},
+		},
+	}
+}
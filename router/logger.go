@@ -0,0 +1,173 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+)
+
+const (
+	correlationIDSize = 8
+	correlationIDAttr = "correlation_id"
+)
+
+type correlationIDKey struct{}
+
+// CorrelationID returns the correlation ID of the update being handled, or empty string.
+// It is set by the Logger middleware.
+func CorrelationID(ctx context.Context) string {
+	res, _ := ctx.Value(correlationIDKey{}).(string)
+
+	return res
+}
+
+// WithCorrelationID returns a copy of ctx with the correlation ID.
+// Logger middleware keeps an existing ID, so it can be set by the update source.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+func newCorrelationID() string {
+	var buf [correlationIDSize]byte
+
+	_, _ = rand.Read(buf[:])
+
+	return hex.EncodeToString(buf[:])
+}
+
+// CorrelationID returns the correlation ID of the update. See CorrelationID.
+func (c *Context) CorrelationID() string {
+	if c.ctx == nil {
+		return ""
+	}
+
+	return CorrelationID(c.ctx)
+}
+
+type loggerConfig struct {
+	raw bool
+}
+
+type LoggerOption func(*loggerConfig)
+
+// LogRawDebug adds the raw update payload to the record.
+// The payload is available only when the router is created with WithDebug option.
+func LogRawDebug() LoggerOption {
+	return func(c *loggerConfig) {
+		c.raw = true
+	}
+}
+
+// Logger writes one record per update.
+//
+// The record contains update ID, kind, chat ID, user ID, matched pattern,
+// duration, accepted flag, errors and the correlation ID.
+// Updates with errors are logged with error level.
+//
+// The correlation ID is stored in Context.Context(), use LogHandler
+// to add it to the records written inside handlers.
+func Logger(logger *slog.Logger, opts ...LoggerOption) Handler {
+	var cfg loggerConfig
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(ctx *Context) {
+		id := ctx.CorrelationID()
+		if id == "" {
+			id = newCorrelationID()
+			ctx.ctx = WithCorrelationID(ctx.ctx, id)
+		}
+
+		start := time.Now()
+
+		ctx.Next()
+
+		duration := time.Since(start)
+		update := ctx.Update()
+
+		attrs := []slog.Attr{
+			slog.String(correlationIDAttr, id),
+			slog.Int64("update_id", update.ID),
+			slog.String("kind", ctx.Kind().String()),
+			slog.String("pattern", ctx.Pattern()),
+			slog.Duration("duration", duration),
+			slog.Bool("accepted", ctx.IsAccepted()),
+		}
+
+		if chatID, ok := ctx.ChatID(); ok {
+			attrs = append(attrs, slog.Int64("chat_id", chatID))
+		}
+
+		if userID, ok := ctx.UserID(); ok {
+			attrs = append(attrs, slog.Int64("user_id", userID))
+		}
+
+		level := slog.LevelInfo
+
+		if errs := ctx.Errors(); len(errs) > 0 {
+			level = slog.LevelError
+
+			messages := make([]string, 0, len(errs))
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+
+			attrs = append(attrs, slog.Any("errors", messages))
+		}
+
+		if raw := ctx.RawDebug(); cfg.raw && raw != nil {
+			attrs = append(attrs, slog.String("raw", string(raw)))
+		}
+
+		logger.LogAttrs(ctx.Context(), level, "update", attrs...)
+	}
+}
+
+// LogHandler wraps the slog handler to add the correlation ID from the record context.
+//
+// Example:
+//
+//	logger := slog.New(router.LogHandler(slog.NewJSONHandler(os.Stdout, nil)))
+//	logger.InfoContext(ctx.Context(), "user created") // has correlation_id attribute
+func LogHandler(handler slog.Handler) slog.Handler {
+	return &correlationHandler{Handler: handler}
+}
+
+type correlationHandler struct {
+	slog.Handler
+}
+
+//nolint:gocritic // slog.Handler interface.
+func (h *correlationHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := CorrelationID(ctx); id != "" && !hasCorrelationID(record) {
+		record.AddAttrs(slog.String(correlationIDAttr, id))
+	}
+
+	//nolint:wrapcheck
+	return h.Handler.Handle(ctx, record)
+}
+
+//nolint:gocritic // slog.Record is passed by value in slog API.
+func hasCorrelationID(record slog.Record) bool {
+	res := false
+
+	record.Attrs(func(attr slog.Attr) bool {
+		res = attr.Key == correlationIDAttr
+
+		return !res
+	})
+
+	return res
+}
+
+func (h *correlationHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &correlationHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *correlationHandler) WithGroup(name string) slog.Handler {
+	return &correlationHandler{Handler: h.Handler.WithGroup(name)}
+}
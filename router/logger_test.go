@@ -0,0 +1,259 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedRecord struct {
+	level slog.Level
+	attrs map[string]any
+}
+
+type captureHandler struct {
+	mu      sync.Mutex
+	records []capturedRecord
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+//nolint:gocritic // slog.Handler interface.
+func (h *captureHandler) Handle(_ context.Context, record slog.Record) error {
+	res := capturedRecord{
+		level: record.Level,
+		attrs: map[string]any{},
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		res.attrs[attr.Key] = attr.Value.Any()
+
+		return true
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records = append(h.records, res)
+
+	return nil
+}
+
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *captureHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+func (h *captureHandler) single(t *testing.T) capturedRecord {
+	t.Helper()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	require.Len(t, h.records, 1)
+
+	return h.records[0]
+}
+
+func TestLogger(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		level    slog.Level
+		pattern  string
+		accepted bool
+		errors   any
+	}{
+		{
+			name:     "accepted",
+			text:     "/ok",
+			level:    slog.LevelInfo,
+			pattern:  "/ok",
+			accepted: true,
+		},
+		{
+			name:     "error",
+			text:     "/fail",
+			level:    slog.LevelError,
+			pattern:  "/fail",
+			accepted: true,
+			errors:   []string{"boom"},
+		},
+		{
+			name:    "not found",
+			text:    "/missing",
+			level:   slog.LevelInfo,
+			pattern: "?",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handler captureHandler
+
+			router := New()
+			router.Use(Logger(slog.New(&handler)))
+			router.NotFound(func(*Context) {})
+			router.Text("/ok", func(ctx *Context) {
+				ctx.Accept()
+			})
+			router.Text("/fail", func(ctx *Context) {
+				ctx.Accept()
+				ctx.Error(errors.New("boom"))
+			})
+
+			update := textUpdate(tc.text)
+			update.ID = 42
+
+			_, _ = router.Handle(context.Background(), update)
+
+			record := handler.single(t)
+			require.Equal(t, tc.level, record.level)
+			require.Equal(t, int64(42), record.attrs["update_id"])
+			require.Equal(t, "message", record.attrs["kind"])
+			require.Equal(t, int64(1), record.attrs["chat_id"])
+			require.Equal(t, int64(1), record.attrs["user_id"])
+			require.Equal(t, tc.pattern, record.attrs["pattern"])
+			require.Equal(t, tc.accepted, record.attrs["accepted"])
+			require.Equal(t, tc.errors, record.attrs["errors"])
+			require.NotEmpty(t, record.attrs[correlationIDAttr])
+			require.NotContains(t, record.attrs, "raw")
+		})
+	}
+}
+
+func TestLogger_raw(t *testing.T) {
+	const body = `{"update_id":1,"message":{"text":"/ok","chat":{"id":1}}}`
+
+	testCases := []struct {
+		name     string
+		debug    bool
+		logRaw   bool
+		expected any
+	}{
+		{name: "debug and raw", debug: true, logRaw: true, expected: body},
+		{name: "raw without debug", logRaw: true},
+		{name: "debug without raw", debug: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				handler    captureHandler
+				opts       []Option
+				loggerOpts []LoggerOption
+			)
+
+			if tc.debug {
+				opts = append(opts, WithDebug())
+			}
+
+			if tc.logRaw {
+				loggerOpts = append(loggerOpts, LogRawDebug())
+			}
+
+			router := New(opts...)
+			router.Use(Logger(slog.New(&handler), loggerOpts...))
+			router.Text("/ok", func(ctx *Context) {
+				ctx.Accept()
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			recorder := httptest.NewRecorder()
+
+			router.HandlerFunc(recorder, req)
+
+			record := handler.single(t)
+			require.Equal(t, tc.expected, record.attrs["raw"])
+		})
+	}
+}
+
+func TestLogger_correlationID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{name: "existing", ctx: WithCorrelationID(context.Background(), "source-id"), expected: "source-id"},
+		{name: "generated", ctx: context.Background()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				handler captureHandler
+				inside  string
+			)
+
+			router := New()
+			router.Use(Logger(slog.New(&handler)))
+			router.Text("/ok", func(ctx *Context) {
+				ctx.Accept()
+
+				inside = ctx.CorrelationID()
+			})
+
+			_, err := router.Handle(tc.ctx, textUpdate("/ok"))
+			require.NoError(t, err)
+
+			record := handler.single(t)
+			require.Equal(t, inside, record.attrs[correlationIDAttr])
+
+			if tc.expected != "" {
+				require.Equal(t, tc.expected, inside)
+			} else {
+				require.Len(t, inside, correlationIDSize*2)
+			}
+		})
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		attrs    []any
+		expected any
+	}{
+		{
+			name:     "from context",
+			ctx:      WithCorrelationID(context.Background(), "ctx-id"),
+			expected: "ctx-id",
+		},
+		{
+			name:     "record attribute kept",
+			ctx:      WithCorrelationID(context.Background(), "ctx-id"),
+			attrs:    []any{correlationIDAttr, "own-id"},
+			expected: "own-id",
+		},
+		{
+			name: "no correlation ID",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handler captureHandler
+
+			logger := slog.New(LogHandler(&handler))
+			logger.InfoContext(tc.ctx, "message", tc.attrs...)
+
+			record := handler.single(t)
+			require.Equal(t, tc.expected, record.attrs[correlationIDAttr])
+		})
+	}
+}
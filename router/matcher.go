@@ -1,6 +1,8 @@
 package router
 
 import (
+	"slices"
+
 	"github.com/opoccomaxao/tg-instrumentation/apimodels"
 	"github.com/opoccomaxao/tg-instrumentation/texts"
 )
@@ -38,18 +40,31 @@ func (l *customCommandList) FindHandler(
 	return nil, false
 }
 
+// textMatcher is implemented by texts.SimpleMatcher and texts.RegexpMatcher.
+type textMatcher interface {
+	MatchIndexes(value string, dst []int) (int, []int)
+	Names() []string
+}
+
 type command struct {
-	matcher  *texts.SimpleMatcher
-	pattern  string
-	handlers []Handler
+	matcher    textMatcher
+	normalizer texts.TextReplacer
+	pattern    string
+	regexp     bool
+	handlers   []Handler
 }
 
+// commandList holds commands of one update kind.
+// Commands are indexed by the radix tree of literal prefixes,
+// regular expressions, patterns starting with a capture and normalized commands are kept in the root.
 type commandList struct {
 	commands []*command
+	tree     radixNode
 }
 
 func (l *commandList) AddHandler(
 	pattern texts.SimplePattern,
+	normalizer texts.TextReplacer,
 	handlers ...Handler,
 ) error {
 	matcher, err := texts.NewSimpleMatcher(pattern)
@@ -58,35 +73,145 @@ func (l *commandList) AddHandler(
 		return err
 	}
 
+	prefix := matcher.Prefix()
+	if normalizer != nil {
+		prefix = ""
+	}
+
+	l.tree.insert(prefix, len(l.commands))
 	l.commands = append(l.commands, &command{
-		matcher:  matcher,
-		pattern:  pattern.String(),
-		handlers: handlers,
+		matcher:    matcher,
+		normalizer: normalizer,
+		pattern:    pattern.String(),
+		handlers:   handlers,
+	})
+
+	return nil
+}
+
+func (l *commandList) AddRegexp(
+	expr string,
+	normalizer texts.TextReplacer,
+	handlers ...Handler,
+) error {
+	matcher, err := texts.NewRegexpMatcher(expr)
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	l.tree.insert("", len(l.commands))
+	l.commands = append(l.commands, &command{
+		matcher:    matcher,
+		normalizer: normalizer,
+		pattern:    expr,
+		regexp:     true,
+		handlers:   handlers,
 	})
 
 	return nil
 }
 
+// FindHandler returns the route of the best matching command with its captures.
+// The command with the highest score wins, simple patterns win ties with regular expressions,
+// otherwise the first registered command wins.
+// Only commands with the literal prefix of the text are matched, in the order of registration.
+// Captures of the best command are kept while the rest are matched into the spare buffer.
+//
+// The text is matched after the normalizer and the normalizer of the command,
+// captures are taken from the text as is.
 func (l *commandList) FindHandler(
 	text string,
-) ([]Handler, string, bool) {
+	normalizer texts.TextReplacer,
+) (route, bool) {
 	var (
-		res      *command
-		maxScore = -1
+		res       *command
+		maxScore  = -1
+		bestValue string
+		best      []int
+		spare     []int
+		buffer    [16]int //nolint:mnd
 	)
 
-	for _, cmd := range l.commands {
-		score := cmd.matcher.Match(text)
+	matched := text
+	if normalizer != nil {
+		matched = normalizer.Execute(text)
+	}
+
+	candidates := l.tree.collect(matched, buffer[:0])
+	slices.Sort(candidates)
+
+	for _, idx := range candidates {
+		cmd := l.commands[idx]
+
+		value := matched
+		if cmd.normalizer != nil {
+			value = cmd.normalizer.Execute(matched)
+		}
+
+		score, indexes := cmd.matcher.MatchIndexes(value, spare[:0])
 
-		if score > maxScore {
+		if score > maxScore || (res != nil && score == maxScore && res.regexp && !cmd.regexp) {
 			maxScore = score
 			res = cmd
+			bestValue = value
+			best, spare = indexes, best
+		} else {
+			spare = indexes
 		}
 	}
 
 	if res == nil {
-		return nil, "", false
+		return route{}, false
+	}
+
+	return route{
+		handlers: res.handlers,
+		pattern:  res.pattern,
+		names:    res.matcher.Names(),
+		captures: originalCaptures(text, bestValue, best, normalizer, res.normalizer),
+	}, true
+}
+
+// originalCaptures takes the captures from the text by their indexes in the normalized value.
+// Spans of the normalized value are mapped back to the text, so captures keep the text as typed.
+func originalCaptures(
+	text string,
+	value string,
+	indexes []int,
+	normalizers ...texts.TextReplacer,
+) []string {
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	var replacer *texts.Replacer
+
+	if value != text {
+		replacer = texts.NewReplacer()
+
+		for _, normalizer := range normalizers {
+			if normalizer != nil {
+				replacer.AddFunc(normalizer.Execute)
+			}
+		}
+	}
+
+	res := make([]string, 0, len(indexes)/2)
+
+	for i := 0; i+1 < len(indexes); i += 2 {
+		start, end := indexes[i], indexes[i+1]
+
+		switch {
+		case start < 0:
+			res = append(res, "")
+		case replacer == nil:
+			res = append(res, text[start:end])
+		default:
+			start, end = texts.OriginalSpan(replacer, text, start, end)
+			res = append(res, text[start:end])
+		}
 	}
 
-	return res.handlers, res.pattern, true
+	return res
 }
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/opoccomaxao/tg-instrumentation/texts"
+	"github.com/stretchr/testify/require"
+)
+
+// linearFindHandler is the reference implementation matching every command.
+func linearFindHandler(l *commandList, text string) (*command, []string) {
+	var (
+		res      *command
+		maxScore = -1
+		best     []string
+	)
+
+	for _, cmd := range l.commands {
+		value := text
+		if cmd.normalizer != nil {
+			value = cmd.normalizer.Execute(text)
+		}
+
+		score, indexes := cmd.matcher.MatchIndexes(value, nil)
+
+		var captures []string
+		for i := 0; i < len(indexes); i += 2 {
+			captures = append(captures, value[max(indexes[i], 0):max(indexes[i+1], 0)])
+		}
+
+		if score > maxScore || (res != nil && score == maxScore && res.regexp && !cmd.regexp) {
+			maxScore = score
+			res = cmd
+			best = captures
+		}
+	}
+
+	return res, best
+}
+
+func newBenchCommandList(tb testing.TB, routes int) *commandList {
+	tb.Helper()
+
+	var list commandList
+
+	for i := range routes {
+		var err error
+
+		switch i % 5 {
+		case 0:
+			err = list.AddHandler(texts.SimplePattern(fmt.Sprintf("/cmd%d", i)), nil)
+		case 1:
+			err = list.AddHandler(texts.SimplePattern(fmt.Sprintf("/cmd%d :id", i)), nil)
+		case 2:
+			err = list.AddHandler(texts.SimplePattern(fmt.Sprintf("menu%d page=*", i)), nil)
+		case 3:
+			err = list.AddHandler(texts.SimplePattern(fmt.Sprintf("/user/%d/*/edit$", i)), nil)
+		case 4:
+			err = list.AddHandler(texts.SimplePattern(fmt.Sprintf("article%d", i)), nil)
+		}
+
+		require.NoError(tb, err)
+	}
+
+	require.NoError(tb, list.AddHandler("*", nil))
+	require.NoError(tb, list.AddRegexp(`#(?P<tag>\w+)`, nil))
+
+	return &list
+}
+
+func benchTexts(routes int) []string {
+	res := make([]string, 0, routes)
+
+	for i := range routes {
+		res = append(res,
+			fmt.Sprintf("/cmd%d", i),
+			fmt.Sprintf("/cmd%d 42 more", i),
+			fmt.Sprintf("menu%d page=2", i),
+			fmt.Sprintf("/user/%d/x/edit", i),
+			fmt.Sprintf("article%d id=1", i),
+			fmt.Sprintf("unknown %d #tag", i),
+		)
+	}
+
+	return res
+}
+
+func TestCommandList_FindHandler(t *testing.T) {
+	const routes = 200
+
+	list := newBenchCommandList(t, routes)
+
+	for _, text := range benchTexts(routes) {
+		expected, captures := linearFindHandler(list, text)
+
+		res, ok := list.FindHandler(text, nil)
+		require.True(t, ok)
+		require.Equal(t, expected.pattern, res.pattern, text)
+		require.Equal(t, captures, res.captures, text)
+	}
+}
+
+func BenchmarkCommandList_FindHandler(b *testing.B) {
+	for _, routes := range []int{10, 100, 500} {
+		list := newBenchCommandList(b, routes)
+		inputs := benchTexts(routes)
+
+		b.Run(fmt.Sprintf("radix/%d", routes), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := range b.N {
+				list.FindHandler(inputs[i%len(inputs)], nil)
+			}
+		})
+
+		b.Run(fmt.Sprintf("linear/%d", routes), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := range b.N {
+				linearFindHandler(list, inputs[i%len(inputs)])
+			}
+		})
+	}
+}
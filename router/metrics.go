@@ -0,0 +1,313 @@
+package router
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultMetricsNamespace = "tg"
+
+//nolint:gochecknoglobals,mnd
+var defaultMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type MetricsOption func(*Metrics)
+
+// WithMetricsNamespace sets the prefix of metric names. Default is "tg".
+func WithMetricsNamespace(namespace string) MetricsOption {
+	return func(m *Metrics) {
+		m.namespace = namespace
+	}
+}
+
+// WithMetricsBuckets sets the upper bounds in seconds of the latency histogram buckets.
+func WithMetricsBuckets(buckets ...float64) MetricsOption {
+	return func(m *Metrics) {
+		m.buckets = slices.Sorted(slices.Values(buckets))
+	}
+}
+
+type routeKey struct {
+	kind    string
+	pattern string
+}
+
+type routeStats struct {
+	total    uint64
+	accepted uint64
+	notFound uint64
+	errors   uint64
+	sum      float64
+	buckets  []uint64
+}
+
+type apiKey struct {
+	method string
+	status string
+}
+
+type apiStats struct {
+	total   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// Metrics collects per-route and Bot API call statistics.
+// It implements http.Handler serving Prometheus text exposition format.
+//
+// Example:
+//
+//	metrics := router.NewMetrics()
+//	r.Use(metrics.Middleware())
+//	http.Handle("/metrics", metrics)
+type Metrics struct {
+	namespace string
+	buckets   []float64
+
+	mu     sync.Mutex
+	routes map[routeKey]*routeStats
+	api    map[apiKey]*apiStats
+}
+
+func NewMetrics(opts ...MetricsOption) *Metrics {
+	res := &Metrics{
+		namespace: defaultMetricsNamespace,
+		buckets:   defaultMetricsBuckets,
+		routes:    map[routeKey]*routeStats{},
+		api:       map[apiKey]*apiStats{},
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// Middleware measures each update and the Bot API calls made through the Context.
+// Register it first with Router.Use to measure the whole chain.
+func (m *Metrics) Middleware() Handler {
+	return func(ctx *Context) {
+		ctx.OnAPICall(m.observeAPICall)
+
+		start := time.Now()
+
+		ctx.Next()
+
+		m.observeUpdate(ctx, time.Since(start))
+	}
+}
+
+func (m *Metrics) bucketIndex(duration time.Duration) int {
+	idx, _ := slices.BinarySearch(m.buckets, duration.Seconds())
+
+	return idx
+}
+
+func (m *Metrics) observeUpdate(ctx *Context, duration time.Duration) {
+	key := routeKey{
+		kind:    ctx.Kind().String(),
+		pattern: ctx.Pattern(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.routes[key]
+	if stats == nil {
+		stats = &routeStats{
+			buckets: make([]uint64, len(m.buckets)+1),
+		}
+		m.routes[key] = stats
+	}
+
+	stats.total++
+	stats.sum += duration.Seconds()
+	stats.buckets[m.bucketIndex(duration)]++
+	stats.errors += uint64(len(ctx.Errors()))
+
+	if ctx.IsAccepted() {
+		stats.accepted++
+	}
+
+	if ctx.IsNotFound() {
+		stats.notFound++
+	}
+}
+
+func (m *Metrics) observeAPICall(_ *Context, method string, duration time.Duration, err error) {
+	key := apiKey{
+		method: method,
+		status: "ok",
+	}
+
+	if err != nil {
+		key.status = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.api[key]
+	if stats == nil {
+		stats = &apiStats{
+			buckets: make([]uint64, len(m.buckets)+1),
+		}
+		m.api[key] = stats
+	}
+
+	stats.total++
+	stats.sum += duration.Seconds()
+	stats.buckets[m.bucketIndex(duration)]++
+}
+
+// ServeHTTP writes all metrics in Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_ = m.WriteText(writer)
+}
+
+// WriteText writes all metrics in Prometheus text exposition format.
+func (m *Metrics) WriteText(output io.Writer) error {
+	writer := bufio.NewWriter(output)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	routes := slices.SortedFunc(maps.Keys(m.routes), func(a, b routeKey) int {
+		return cmp.Or(strings.Compare(a.kind, b.kind), strings.Compare(a.pattern, b.pattern))
+	})
+
+	api := slices.SortedFunc(maps.Keys(m.api), func(a, b apiKey) int {
+		return cmp.Or(strings.Compare(a.method, b.method), strings.Compare(a.status, b.status))
+	})
+
+	routeCounter := func(name string, help string, value func(*routeStats) uint64) {
+		m.writeHeader(writer, name, help, "counter")
+
+		for _, key := range routes {
+			writeSample(writer, m.name(name), routeLabels(key), float64(value(m.routes[key])))
+		}
+	}
+
+	routeCounter("updates_total", "Total number of handled updates.", func(s *routeStats) uint64 {
+		return s.total
+	})
+	routeCounter("updates_accepted_total", "Number of accepted updates.", func(s *routeStats) uint64 {
+		return s.accepted
+	})
+	routeCounter("updates_not_found_total", "Number of updates without matching route.", func(s *routeStats) uint64 {
+		return s.notFound
+	})
+	routeCounter("update_errors_total", "Number of errors collected by handlers.", func(s *routeStats) uint64 {
+		return s.errors
+	})
+
+	m.writeHeader(writer, "update_duration_seconds", "Update handling latency.", "histogram")
+
+	for _, key := range routes {
+		stats := m.routes[key]
+		m.writeHistogram(writer, "update_duration_seconds", routeLabels(key), stats.buckets, stats.sum, stats.total)
+	}
+
+	m.writeHeader(writer, "api_calls_total", "Total number of Bot API calls.", "counter")
+
+	for _, key := range api {
+		writeSample(writer, m.name("api_calls_total"), apiLabels(key), float64(m.api[key].total))
+	}
+
+	m.writeHeader(writer, "api_call_duration_seconds", "Bot API call latency.", "histogram")
+
+	for _, key := range api {
+		stats := m.api[key]
+		m.writeHistogram(writer, "api_call_duration_seconds", apiLabels(key), stats.buckets, stats.sum, stats.total)
+	}
+
+	return errors.WithStack(writer.Flush())
+}
+
+func (m *Metrics) name(name string) string {
+	if m.namespace == "" {
+		return name
+	}
+
+	return m.namespace + "_" + name
+}
+
+// Write errors of bufio.Writer are sticky, WriteText reports them on Flush.
+func (m *Metrics) writeHeader(writer *bufio.Writer, name string, help string, kind string) {
+	_, _ = fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n", m.name(name), help, m.name(name), kind)
+}
+
+func (m *Metrics) writeHistogram(
+	writer *bufio.Writer,
+	name string,
+	labels []string,
+	buckets []uint64,
+	sum float64,
+	count uint64,
+) {
+	var cumulative uint64
+
+	for i, bound := range m.buckets {
+		cumulative += buckets[i]
+		writeSample(writer, m.name(name)+"_bucket", append(labels, "le", formatFloat(bound)), float64(cumulative))
+	}
+
+	writeSample(writer, m.name(name)+"_bucket", append(labels, "le", "+Inf"), float64(count))
+	writeSample(writer, m.name(name)+"_sum", labels, sum)
+	writeSample(writer, m.name(name)+"_count", labels, float64(count))
+}
+
+func routeLabels(key routeKey) []string {
+	return []string{"kind", key.kind, "pattern", key.pattern}
+}
+
+func apiLabels(key apiKey) []string {
+	return []string{"method", key.method, "status", key.status}
+}
+
+// writeSample writes a sample line. Labels are name-value pairs.
+// Write errors are sticky, see writeHeader.
+func writeSample(writer *bufio.Writer, name string, labels []string, value float64) {
+	_, _ = writer.WriteString(name)
+
+	if len(labels) > 0 {
+		_ = writer.WriteByte('{')
+
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				_ = writer.WriteByte(',')
+			}
+
+			_, _ = writer.WriteString(labels[i])
+			_, _ = writer.WriteString(`="`)
+			_, _ = writer.WriteString(labelReplacer.Replace(labels[i+1]))
+			_ = writer.WriteByte('"')
+		}
+
+		_ = writer.WriteByte('}')
+	}
+
+	_ = writer.WriteByte(' ')
+	_, _ = writer.WriteString(formatFloat(value))
+	_ = writer.WriteByte('\n')
+}
+
+//nolint:gochecknoglobals
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,93 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	client, _ := newTestBot(t, func(writer http.ResponseWriter, req *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+
+		if req.FormValue("chat_id") == "2" {
+			_, _ = writer.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request"}`))
+
+			return
+		}
+
+		_, _ = writer.Write([]byte(sendMessageResponse))
+	})
+
+	metrics := NewMetrics(WithMetricsBuckets(60))
+
+	router := New(WithClient(client))
+	router.Use(metrics.Middleware())
+	router.Text("/send", func(ctx *Context) {
+		_, err := ctx.SendMessage(&bot.SendMessageParams{ChatID: 1, Text: "ok"})
+		if err != nil {
+			ctx.Error(err)
+		}
+	})
+	router.Text("/fail", func(ctx *Context) {
+		_, err := ctx.SendMessage(&bot.SendMessageParams{ChatID: 2, Text: "fail"})
+		if err != nil {
+			ctx.Error(err)
+		}
+	})
+
+	for _, text := range []string{"/send", "/send", "/fail", "/missing"} {
+		_, _ = router.Handle(context.Background(), textUpdate(text))
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	body := recorder.Body.String()
+
+	for _, line := range []string{
+		"# TYPE tg_updates_total counter\n",
+		`tg_updates_total{kind="message",pattern="/send"} 2` + "\n",
+		`tg_updates_total{kind="message",pattern="/fail"} 1` + "\n",
+		`tg_updates_total{kind="message",pattern="?"} 1` + "\n",
+		`tg_updates_accepted_total{kind="message",pattern="/send"} 2` + "\n",
+		`tg_updates_accepted_total{kind="message",pattern="/fail"} 0` + "\n",
+		`tg_updates_accepted_total{kind="message",pattern="?"} 1` + "\n",
+		`tg_updates_not_found_total{kind="message",pattern="/send"} 0` + "\n",
+		`tg_updates_not_found_total{kind="message",pattern="?"} 1` + "\n",
+		`tg_update_errors_total{kind="message",pattern="/send"} 0` + "\n",
+		`tg_update_errors_total{kind="message",pattern="/fail"} 1` + "\n",
+		"# TYPE tg_update_duration_seconds histogram\n",
+		`tg_update_duration_seconds_bucket{kind="message",pattern="/send",le="60"} 2` + "\n",
+		`tg_update_duration_seconds_bucket{kind="message",pattern="/send",le="+Inf"} 2` + "\n",
+		`tg_update_duration_seconds_count{kind="message",pattern="/send"} 2` + "\n",
+		"# TYPE tg_api_calls_total counter\n",
+		`tg_api_calls_total{method="sendMessage",status="error"} 1` + "\n",
+		`tg_api_calls_total{method="sendMessage",status="ok"} 2` + "\n",
+		"# TYPE tg_api_call_duration_seconds histogram\n",
+		`tg_api_call_duration_seconds_bucket{method="sendMessage",status="ok",le="60"} 2` + "\n",
+		`tg_api_call_duration_seconds_bucket{method="sendMessage",status="ok",le="+Inf"} 2` + "\n",
+		`tg_api_call_duration_seconds_count{method="sendMessage",status="error"} 1` + "\n",
+	} {
+		require.Contains(t, body, line)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestMetrics_WriteTextError(t *testing.T) {
+	metrics := NewMetrics()
+
+	require.Error(t, metrics.WriteText(failingWriter{}))
+}
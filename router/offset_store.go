@@ -0,0 +1,94 @@
+package router
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/opoccomaxao/tg-instrumentation/storage"
+	"github.com/pkg/errors"
+)
+
+// OffsetStore persists the getUpdates offset between restarts.
+//
+// The stored value is the offset of the next update to request,
+// i.e. the ID of the last processed update plus one.
+type OffsetStore interface {
+	LoadOffset(ctx context.Context) (int64, error)
+	SaveOffset(ctx context.Context, offset int64) error
+}
+
+// MemoryOffsetStore keeps the offset in memory. It is used by default.
+type MemoryOffsetStore struct {
+	mu     sync.Mutex
+	offset int64
+}
+
+func NewMemoryOffsetStore() *MemoryOffsetStore {
+	return &MemoryOffsetStore{}
+}
+
+func (s *MemoryOffsetStore) LoadOffset(context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.offset, nil
+}
+
+func (s *MemoryOffsetStore) SaveOffset(_ context.Context, offset int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset = offset
+
+	return nil
+}
+
+// FileOffsetStore keeps the offset in a plain text file.
+// Missing file is treated as zero offset.
+type FileOffsetStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{
+		path: path,
+	}
+}
+
+func (s *FileOffsetStore) LoadOffset(context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+
+		return 0, errors.WithStack(err)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return 0, nil
+	}
+
+	res, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(ErrFailed, "invalid offset in %s: %v", s.path, err)
+	}
+
+	return res, nil
+}
+
+func (s *FileOffsetStore) SaveOffset(_ context.Context, offset int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	//nolint:wrapcheck
+	return storage.WriteFileAtomic(s.path, []byte(strconv.FormatInt(offset, 10)))
+}
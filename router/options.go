@@ -1,6 +1,12 @@
 package router
 
-import "github.com/go-telegram/bot"
+import (
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/opoccomaxao/tg-instrumentation/query"
+	"github.com/opoccomaxao/tg-instrumentation/texts"
+)
 
 type Option func(*Router)
 
@@ -15,3 +21,98 @@ func WithDebug() Option {
 		r.debug = true
 	}
 }
+
+// WithBotUsername sets the username of the bot without "@".
+// Commands addressed to the bot as "/cmd@username" are matched as "/cmd",
+// commands addressed to other bots are accepted without calling the routes,
+// only middlewares see them with "@" pattern. Use Router.FetchBotUsername to load it with getMe.
+func WithBotUsername(username string) Option {
+	return func(r *Router) {
+		r.botUsername = strings.TrimPrefix(username, "@")
+	}
+}
+
+// WithNormalizer sets the normalizer of texts typed by users: messages, channel posts,
+// business messages and inline queries. Callback data and invoice payloads are kept as is.
+// Patterns of these kinds are normalized the same way when registered, so the option must go
+// before route registration. The text is normalized only for matching:
+// Context.Text, Context.Query and captures keep the text as typed by the user.
+// Regular expressions are matched against the normalized text but are not normalized themselves,
+// write them against the normalized form, e.g. `(?i)^/big (\d+)` instead of `^/Big (\d+)`.
+//
+// Example:
+//
+//	r := router.New(router.WithNormalizer(texts.NewNormalizer(texts.NormalizeAll)))
+func WithNormalizer(normalizer texts.TextReplacer) Option {
+	return func(r *Router) {
+		r.normalizer = normalizer
+	}
+}
+
+// WithSecretToken sets the expected X-Telegram-Bot-Api-Secret-Token header value.
+// Webhook requests with a missing or different token are rejected before decoding.
+// Use Router.SetWebhook to register the webhook with the same token.
+func WithSecretToken(token string) Option {
+	return func(r *Router) {
+		r.secretToken = token
+	}
+}
+
+// WithFloodLimiter enables outgoing flood control for requests made through the Context.
+// Requests wait for the limiter and are retried after 429 Too Many Requests.
+func WithFloodLimiter(limiter *FloodLimiter) Option {
+	return func(r *Router) {
+		r.flood = limiter
+	}
+}
+
+// WithStateStore sets the store of conversation states. Default is in-memory store.
+func WithStateStore(store StateStore) Option {
+	return func(r *Router) {
+		r.stateStore = store
+	}
+}
+
+// WithStateKey sets how updates are grouped into conversations. Default is StateKeyChatUser.
+func WithStateKey(keyFunc StateKeyFunc) Option {
+	return func(r *Router) {
+		r.stateKey = keyFunc
+	}
+}
+
+// WithBindErrorHandler sets the handler of query decoding errors in Bind handlers.
+// Default is DefaultBindErrorHandler.
+func WithBindErrorHandler(handler BindErrorHandler) Option {
+	return func(r *Router) {
+		r.bindError = handler
+	}
+}
+
+// WithCallbackEncoder enables tokens for long callback data.
+// Tokens in incoming callback queries are expanded before matching,
+// Context.EncodeCallback uses the encoder to build callback data.
+func WithCallbackEncoder(encoder *query.CallbackEncoder) Option {
+	return func(r *Router) {
+		r.callbackEncoder = encoder
+	}
+}
+
+// WithCallbackCodec sets the codec used by Context.EncodeCallback. Default is query.TextCodec.
+// Incoming callback data in compact form is decoded with the codec if it implements query.CompactDecoder,
+// otherwise without key aliases, and matched in the text form, so both forms can be used at the same time.
+// Data that only starts with query.CompactPrefix is matched as is.
+func WithCallbackCodec(codec query.Codec) Option {
+	return func(r *Router) {
+		r.callbackCodec = codec
+	}
+}
+
+// WithCallbackSigner enables signed callback data.
+// Signatures of incoming callback queries are verified and stripped before matching,
+// use SignedCallbacks middleware to reject invalid ones.
+// Context.EncodeCallback signs the data before the token replacement.
+func WithCallbackSigner(signer *query.Signer) Option {
+	return func(r *Router) {
+		r.callbackSigner = signer
+	}
+}
@@ -0,0 +1,307 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPollServerURL  = "https://api.telegram.org"
+	defaultPollTimeout    = 30 * time.Second
+	defaultPollMinBackoff = 100 * time.Millisecond
+	defaultPollMaxBackoff = 30 * time.Second
+	pollRequestTimeoutGap = 10 * time.Second
+)
+
+// UpdateHandler processes a single update. It is implemented by *Router.
+type UpdateHandler interface {
+	Handle(ctx context.Context, update *apimodels.Update, opts ...ContextOption) (bool, error)
+}
+
+type PollerOption func(*Poller)
+
+// WithPollTimeout sets the long polling timeout. Default is 30 seconds.
+func WithPollTimeout(timeout time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.timeout = timeout
+	}
+}
+
+// WithPollLimit limits the number of updates fetched per request. Telegram allows 1-100.
+func WithPollLimit(limit int) PollerOption {
+	return func(p *Poller) {
+		p.limit = limit
+	}
+}
+
+// WithAllowedUpdates sets the list of update kinds to receive.
+// See https://core.telegram.org/bots/api#getupdates for details.
+func WithAllowedUpdates(kinds ...string) PollerOption {
+	return func(p *Poller) {
+		p.allowedUpdates = kinds
+	}
+}
+
+// WithOffsetStore sets the store used to persist the offset between restarts.
+func WithOffsetStore(store OffsetStore) PollerOption {
+	return func(p *Poller) {
+		p.store = store
+	}
+}
+
+// WithPollBackoff sets the bounds of the exponential backoff applied after failed requests.
+func WithPollBackoff(minDelay time.Duration, maxDelay time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.minBackoff = minDelay
+		p.maxBackoff = maxDelay
+	}
+}
+
+// WithPollServerURL overrides the Bot API server URL.
+func WithPollServerURL(serverURL string) PollerOption {
+	return func(p *Poller) {
+		p.serverURL = serverURL
+	}
+}
+
+// WithPollHTTPClient sets the HTTP client used for getUpdates requests.
+func WithPollHTTPClient(client *http.Client) PollerOption {
+	return func(p *Poller) {
+		p.httpClient = client
+	}
+}
+
+// WithPollErrorHandler sets the callback for request and handler errors.
+// Errors are never fatal for the poller.
+func WithPollErrorHandler(handler func(error)) PollerOption {
+	return func(p *Poller) {
+		p.onError = handler
+	}
+}
+
+// Poller receives updates with getUpdates and feeds them to the handler.
+type Poller struct {
+	client         *bot.Bot
+	handler        UpdateHandler
+	httpClient     *http.Client
+	serverURL      string
+	timeout        time.Duration
+	limit          int
+	allowedUpdates []string
+	store          OffsetStore
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	onError        func(error)
+}
+
+func NewPoller(
+	client *bot.Bot,
+	handler UpdateHandler,
+	opts ...PollerOption,
+) *Poller {
+	res := &Poller{
+		client:     client,
+		handler:    handler,
+		httpClient: http.DefaultClient,
+		serverURL:  defaultPollServerURL,
+		timeout:    defaultPollTimeout,
+		store:      NewMemoryOffsetStore(),
+		minBackoff: defaultPollMinBackoff,
+		maxBackoff: defaultPollMaxBackoff,
+		onError:    func(error) {},
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// Poller creates a long-polling update source for the router.
+// The router must be created with WithClient option.
+func (r *Router) Poller(opts ...PollerOption) *Poller {
+	return NewPoller(r.client, r, opts...)
+}
+
+// Run fetches and handles updates until the context is cancelled.
+// Returns nil on cancellation and an error only if the initial offset cannot be loaded.
+//
+// Offset is saved after each handled update, so a restart neither replays nor skips updates.
+// Updates left in the batch after cancellation are not handled, and the update whose handling
+// failed because of cancellation is not marked as handled, so they are fetched again after restart.
+func (p *Poller) Run(ctx context.Context) error {
+	if p.client == nil {
+		return errors.Wrap(ErrFailed, "client is not set")
+	}
+
+	offset, err := p.store.LoadOffset(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "load offset")
+	}
+
+	var backoff time.Duration
+
+	for ctx.Err() == nil {
+		updates, err := p.getUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
+			p.onError(err)
+
+			backoff = p.nextBackoff(backoff, err)
+			if !sleepContext(ctx, backoff) {
+				break
+			}
+
+			continue
+		}
+
+		backoff = 0
+
+		for _, update := range updates {
+			if ctx.Err() != nil {
+				break
+			}
+
+			_, err := p.handler.Handle(ctx, update)
+			if err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+
+				p.onError(errors.WithMessagef(err, "update %d", update.ID))
+			}
+
+			offset = update.ID + 1
+
+			err = p.store.SaveOffset(context.WithoutCancel(ctx), offset)
+			if err != nil {
+				p.onError(errors.WithMessage(err, "save offset"))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Poller) nextBackoff(current time.Duration, err error) time.Duration {
+	var tooMany *bot.TooManyRequestsError
+	if errors.As(err, &tooMany) && tooMany.RetryAfter > 0 {
+		return time.Duration(tooMany.RetryAfter) * time.Second
+	}
+
+	if current < p.minBackoff {
+		return p.minBackoff
+	}
+
+	return min(current*2, p.maxBackoff) //nolint:mnd
+}
+
+type getUpdatesParams struct {
+	Offset         int64    `json:"offset,omitempty"`
+	Limit          int      `json:"limit,omitempty"`
+	Timeout        int      `json:"timeout,omitempty"`
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
+}
+
+type getUpdatesResponse struct {
+	OK          bool                `json:"ok"`
+	Result      []*apimodels.Update `json:"result"`
+	ErrorCode   int                 `json:"error_code"`
+	Description string              `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+func (p *Poller) getUpdates(
+	ctx context.Context,
+	offset int64,
+) ([]*apimodels.Update, error) {
+	body, err := json.Marshal(getUpdatesParams{
+		Offset:         offset,
+		Limit:          p.limit,
+		Timeout:        int(p.timeout.Seconds()),
+		AllowedUpdates: p.allowedUpdates,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout+pollRequestTimeoutGap)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		p.serverURL+"/bot"+p.client.Token()+"/getUpdates",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		// Hide the URL, it contains the token.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return nil, errors.Wrap(err, "getUpdates")
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "getUpdates")
+	}
+
+	var res getUpdatesResponse
+
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getUpdates: status %d", resp.StatusCode)
+	}
+
+	if !res.OK {
+		if res.ErrorCode == http.StatusTooManyRequests {
+			return nil, &bot.TooManyRequestsError{
+				Message:    res.Description,
+				RetryAfter: res.Parameters.RetryAfter,
+			}
+		}
+
+		return nil, errors.Wrapf(ErrFailed, "getUpdates: %d %s", res.ErrorCode, res.Description)
+	}
+
+	return res.Result, nil
+}
+
+// sleepContext waits for the duration. Returns false if the context is done first.
+func sleepContext(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,283 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGetUpdates serves getUpdates with the batches by offset and records requested offsets.
+type fakeGetUpdates struct {
+	mu        sync.Mutex
+	offsets   []int64
+	responses map[int64][]string // offset -> queue of response bodies.
+}
+
+func (f *fakeGetUpdates) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	var params getUpdatesParams
+
+	_ = json.NewDecoder(req.Body).Decode(&params)
+
+	f.mu.Lock()
+	f.offsets = append(f.offsets, params.Offset)
+
+	body := `{"ok":true,"result":[]}`
+	if queue := f.responses[params.Offset]; len(queue) > 0 {
+		body, f.responses[params.Offset] = queue[0], queue[1:]
+	}
+	f.mu.Unlock()
+
+	if body == `{"ok":true,"result":[]}` {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = writer.Write([]byte(body))
+}
+
+func (f *fakeGetUpdates) requested() []int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]int64(nil), f.offsets...)
+}
+
+func updatesBody(ids ...int64) string {
+	updates := make([]*apimodels.Update, len(ids))
+	for i, id := range ids {
+		updates[i] = &apimodels.Update{ID: id}
+	}
+
+	data, _ := json.Marshal(map[string]any{"ok": true, "result": updates})
+
+	return string(data)
+}
+
+func newTestPoller(
+	t *testing.T,
+	fake *fakeGetUpdates,
+	handler UpdateHandler,
+	opts ...PollerOption,
+) *Poller {
+	t.Helper()
+
+	client, server := newTestBot(t, fake.ServeHTTP)
+
+	return NewPoller(client, handler, append([]PollerOption{WithPollServerURL(server.URL)}, opts...)...)
+}
+
+func runPoller(t *testing.T, ctx context.Context, poller *Poller) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, poller.Run(ctx))
+	require.Error(t, ctx.Err(), "poller must stop only on cancellation")
+}
+
+func TestPoller_offset(t *testing.T) {
+	fake := &fakeGetUpdates{
+		responses: map[int64][]string{
+			5: {updatesBody(5, 6, 7)},
+			8: {updatesBody(8)},
+		},
+	}
+	store := NewMemoryOffsetStore()
+	require.NoError(t, store.SaveOffset(context.Background(), 5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []int64
+
+	poller := newTestPoller(t, fake, updateHandlerFunc(func(_ context.Context, update *apimodels.Update) (bool, error) {
+		handled = append(handled, update.ID)
+		if update.ID == 8 {
+			cancel()
+		}
+
+		return true, nil
+	}), WithOffsetStore(store))
+
+	runPoller(t, ctx, poller)
+
+	require.Equal(t, []int64{5, 6, 7, 8}, handled)
+	require.Equal(t, []int64{5, 8}, fake.requested())
+
+	offset, err := store.LoadOffset(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(9), offset)
+}
+
+func TestPoller_cancelInBatch(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handle  func(cancel context.CancelFunc, ctx context.Context, id int64) error
+		handled []int64
+		offset  int64
+	}{
+		{
+			name: "handled before cancel",
+			handle: func(cancel context.CancelFunc, _ context.Context, id int64) error {
+				if id == 1 {
+					cancel()
+				}
+
+				return nil
+			},
+			handled: []int64{1},
+			offset:  2,
+		},
+		{
+			name: "failed by cancel",
+			handle: func(cancel context.CancelFunc, ctx context.Context, id int64) error {
+				if id == 2 {
+					cancel()
+
+					return errors.WithStack(ctx.Err())
+				}
+
+				return nil
+			},
+			handled: []int64{1, 2},
+			offset:  2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeGetUpdates{
+				responses: map[int64][]string{
+					0: {updatesBody(1, 2, 3)},
+				},
+			}
+			store := NewMemoryOffsetStore()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var handled []int64
+
+			poller := newTestPoller(t, fake, updateHandlerFunc(func(ctx context.Context, update *apimodels.Update) (bool, error) {
+				handled = append(handled, update.ID)
+
+				return true, tc.handle(cancel, ctx, update.ID)
+			}), WithOffsetStore(store))
+
+			runPoller(t, ctx, poller)
+
+			require.Equal(t, tc.handled, handled)
+
+			offset, err := store.LoadOffset(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tc.offset, offset)
+		})
+	}
+}
+
+func TestPoller_backoff(t *testing.T) {
+	fake := &fakeGetUpdates{
+		responses: map[int64][]string{
+			0: {
+				`{"ok":false,"error_code":500,"description":"boom"}`,
+				`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":1}}`,
+				updatesBody(1),
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		errs  []error
+		times []time.Time
+	)
+
+	poller := newTestPoller(t, fake, updateHandlerFunc(func(context.Context, *apimodels.Update) (bool, error) {
+		times = append(times, time.Now())
+		cancel()
+
+		return true, nil
+	}),
+		WithPollBackoff(time.Millisecond, 10*time.Millisecond),
+		WithPollErrorHandler(func(err error) {
+			errs = append(errs, err)
+			times = append(times, time.Now())
+		}),
+	)
+
+	runPoller(t, ctx, poller)
+
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[0], ErrFailed)
+	require.NotContains(t, errs[0].Error(), testToken)
+
+	var tooMany *bot.TooManyRequestsError
+	require.ErrorAs(t, errs[1], &tooMany)
+	require.Equal(t, 1, tooMany.RetryAfter)
+
+	require.Len(t, times, 3)
+	require.GreaterOrEqual(t, times[2].Sub(times[1]), time.Second)
+}
+
+func TestPoller_nextBackoff(t *testing.T) {
+	poller := NewPoller(nil, nil, WithPollBackoff(100*time.Millisecond, time.Second))
+	failed := errors.New("failed")
+
+	testCases := []struct {
+		current time.Duration
+		err     error
+		next    time.Duration
+	}{
+		{current: 0, err: failed, next: 100 * time.Millisecond},
+		{current: 100 * time.Millisecond, err: failed, next: 200 * time.Millisecond},
+		{current: 800 * time.Millisecond, err: failed, next: time.Second},
+		{current: time.Second, err: failed, next: time.Second},
+		{current: 0, err: &bot.TooManyRequestsError{RetryAfter: 5}, next: 5 * time.Second},
+		{current: time.Second, err: &bot.TooManyRequestsError{}, next: time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %v", tc.current, tc.err), func(t *testing.T) {
+			require.Equal(t, tc.next, poller.nextBackoff(tc.current, tc.err))
+		})
+	}
+}
+
+func TestFileOffsetStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "offset")
+	store := NewFileOffsetStore(path)
+
+	offset, err := store.LoadOffset(ctx)
+	require.NoError(t, err)
+	require.Zero(t, offset)
+
+	require.NoError(t, store.SaveOffset(ctx, 42))
+	require.NoError(t, store.SaveOffset(ctx, 43))
+
+	offset, err = NewFileOffsetStore(path).LoadOffset(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(43), offset)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files must be renamed")
+
+	require.NoError(t, os.WriteFile(path, []byte("abc"), 0o600))
+
+	_, err = store.LoadOffset(ctx)
+	require.ErrorIs(t, err, ErrFailed)
+}
@@ -0,0 +1,76 @@
+package router
+
+import "strings"
+
+// radixNode is a node of the radix tree of literal pattern prefixes.
+// Each node keeps the commands whose prefix ends at the node,
+// so the commands that can match the text are found in one walk along the text.
+type radixNode struct {
+	label    string
+	children map[byte]*radixNode
+	commands []int // indexes in commandList.commands.
+}
+
+func (n *radixNode) insert(prefix string, command int) {
+	for prefix != "" {
+		child := n.children[prefix[0]]
+		if child == nil {
+			if n.children == nil {
+				n.children = map[byte]*radixNode{}
+			}
+
+			n.children[prefix[0]] = &radixNode{
+				label:    prefix,
+				commands: []int{command},
+			}
+
+			return
+		}
+
+		common := commonPrefixLen(child.label, prefix)
+		if common < len(child.label) {
+			split := &radixNode{
+				label:    child.label[:common],
+				children: map[byte]*radixNode{child.label[common]: child},
+			}
+
+			child.label = child.label[common:]
+			n.children[prefix[0]] = split
+			child = split
+		}
+
+		n, prefix = child, prefix[common:]
+	}
+
+	n.commands = append(n.commands, command)
+}
+
+// collect appends the commands whose prefixes are prefixes of the text.
+func (n *radixNode) collect(text string, dst []int) []int {
+	for {
+		dst = append(dst, n.commands...)
+
+		if text == "" {
+			return dst
+		}
+
+		child := n.children[text[0]]
+		if child == nil || !strings.HasPrefix(text, child.label) {
+			return dst
+		}
+
+		n, text = child, text[len(child.label):]
+	}
+}
+
+func commonPrefixLen(a string, b string) int {
+	size := min(len(a), len(b))
+
+	for i := range size {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return size
+}
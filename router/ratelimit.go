@@ -0,0 +1,137 @@
+package router
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+const minRateLimitIdle = time.Minute
+
+// RateLimitKeyFunc returns the key of the bucket for the update.
+// Updates without a key are not limited.
+type RateLimitKeyFunc func(ctx *Context) (string, bool)
+
+// RateLimitByUser limits updates per user. It is the default key.
+func RateLimitByUser(ctx *Context) (string, bool) {
+	id, ok := ctx.UserID()
+	if !ok {
+		return "", false
+	}
+
+	return strconv.FormatInt(id, 10), true
+}
+
+// RateLimitByChat limits updates per chat.
+func RateLimitByChat(ctx *Context) (string, bool) {
+	id, ok := ctx.ChatID()
+	if !ok {
+		return "", false
+	}
+
+	return strconv.FormatInt(id, 10), true
+}
+
+type RateLimitOption func(*rateLimiter)
+
+// WithRateLimitKey sets the bucket key function. Default is RateLimitByUser.
+func WithRateLimitKey(keyFunc RateLimitKeyFunc) RateLimitOption {
+	return func(l *rateLimiter) {
+		l.keyFunc = keyFunc
+	}
+}
+
+// WithRateLimitHandler sets the handler called instead of the chain for throttled updates.
+// Default handler accepts the update silently.
+func WithRateLimitHandler(handler Handler) RateLimitOption {
+	return func(l *rateLimiter) {
+		l.throttled = handler
+	}
+}
+
+// WithRateLimitIdle sets how long an unused bucket is kept in memory.
+// It is never shorter than the time needed to refill the bucket.
+func WithRateLimitIdle(idle time.Duration) RateLimitOption {
+	return func(l *rateLimiter) {
+		l.idle = idle
+	}
+}
+
+// RateLimitAnswer returns a throttled handler that answers callback queries with the toast text.
+// Other updates are accepted silently.
+func RateLimitAnswer(text string) Handler {
+	return func(ctx *Context) {
+		update := ctx.Update()
+		if update.CallbackQuery == nil {
+			ctx.Accept()
+
+			return
+		}
+
+		ctx.LogError2(ctx.AnswerCallbackQuery(&bot.AnswerCallbackQueryParams{
+			CallbackQueryID: update.CallbackQuery.ID,
+			Text:            text,
+		}))
+	}
+}
+
+type rateLimiter struct {
+	keyFunc   RateLimitKeyFunc
+	throttled Handler
+	idle      time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets *tokenBuckets
+}
+
+// RateLimit limits handled updates with a token bucket per key.
+// Each bucket holds up to burst tokens and is refilled with rate tokens per second.
+// Updates without tokens are passed to the throttled handler, the chain is not called.
+//
+// Example:
+//
+//	r.Use(router.RateLimit(1, 5, router.WithRateLimitHandler(router.RateLimitAnswer("Slow down"))))
+func RateLimit(rate float64, burst int, opts ...RateLimitOption) Handler {
+	return newRateLimiter(rate, burst, opts...).handle
+}
+
+func newRateLimiter(rate float64, burst int, opts ...RateLimitOption) *rateLimiter {
+	res := &rateLimiter{
+		keyFunc:   RateLimitByUser,
+		throttled: AutoAccept(),
+		now:       time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	res.buckets = newTokenBuckets(rate, burst, max(res.idle, minRateLimitIdle))
+
+	return res
+}
+
+func (l *rateLimiter) handle(ctx *Context) {
+	key, ok := l.keyFunc(ctx)
+	if ok && !l.allow(key) {
+		ctx.Abort()
+
+		if l.throttled != nil {
+			l.throttled(ctx)
+		}
+
+		return
+	}
+
+	ctx.Next()
+}
+
+func (l *rateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.buckets.allow(key, l.now())
+}
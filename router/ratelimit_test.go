@@ -0,0 +1,212 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock is the manually advanced clock for the now hooks.
+type testClock struct {
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func userUpdate(userID int64) *apimodels.Update {
+	return &apimodels.Update{
+		Message: &models.Message{
+			Text: "/cmd",
+			Chat: models.Chat{ID: userID},
+			From: &models.User{ID: userID},
+		},
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	type step struct {
+		after   time.Duration
+		user    int64
+		allowed bool
+	}
+
+	testCases := []struct {
+		name  string
+		rate  float64
+		burst int
+		steps []step
+	}{
+		{
+			name:  "burst",
+			rate:  1,
+			burst: 2,
+			steps: []step{
+				{user: 1, allowed: true},
+				{user: 1, allowed: true},
+				{user: 1, allowed: false},
+				{user: 2, allowed: true},
+			},
+		},
+		{
+			name:  "refill",
+			rate:  2,
+			burst: 1,
+			steps: []step{
+				{user: 1, allowed: true},
+				{after: 250 * time.Millisecond, user: 1, allowed: false},
+				{after: 250 * time.Millisecond, user: 1, allowed: true},
+				{user: 1, allowed: false},
+			},
+		},
+		{
+			name:  "refill up to burst",
+			rate:  1,
+			burst: 2,
+			steps: []step{
+				{user: 1, allowed: true},
+				{user: 1, allowed: true},
+				{after: time.Hour, user: 1, allowed: true},
+				{user: 1, allowed: true},
+				{user: 1, allowed: false},
+			},
+		},
+		{
+			name:  "zero rate",
+			rate:  0,
+			burst: 1,
+			steps: []step{
+				{user: 1, allowed: true},
+				{after: time.Second, user: 1, allowed: false},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handled, throttled int
+
+			clock := newTestClock()
+			limiter := newRateLimiter(tc.rate, tc.burst, WithRateLimitHandler(func(ctx *Context) {
+				throttled++
+
+				ctx.Accept()
+			}))
+			limiter.now = clock.Now
+
+			router := New()
+			router.Use(limiter.handle)
+			router.Text("/cmd", func(ctx *Context) {
+				handled++
+
+				ctx.Accept()
+			})
+
+			for i, step := range tc.steps {
+				clock.Add(step.after)
+
+				handledBefore, throttledBefore := handled, throttled
+
+				accepted, err := router.Handle(context.Background(), userUpdate(step.user))
+				require.NoError(t, err)
+				require.True(t, accepted)
+
+				if step.allowed {
+					require.Equal(t, handledBefore+1, handled, "step %d", i)
+					require.Equal(t, throttledBefore, throttled, "step %d", i)
+				} else {
+					require.Equal(t, handledBefore, handled, "step %d", i)
+					require.Equal(t, throttledBefore+1, throttled, "step %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimit_idle(t *testing.T) {
+	clock := newTestClock()
+	limiter := newRateLimiter(1, 1, WithRateLimitIdle(2*time.Minute))
+	limiter.now = clock.Now
+
+	require.True(t, limiter.allow("1"))
+	require.True(t, limiter.allow("2"))
+	require.Len(t, limiter.buckets.buckets, 2)
+
+	clock.Add(time.Minute)
+	require.True(t, limiter.allow("2"))
+	require.Len(t, limiter.buckets.buckets, 2, "buckets must be kept until idle")
+
+	clock.Add(time.Minute + time.Second)
+	require.True(t, limiter.allow("2"))
+	require.Len(t, limiter.buckets.buckets, 1, "idle bucket must be evicted")
+	require.Contains(t, limiter.buckets.buckets, "2")
+}
+
+func TestRateLimit_idleMin(t *testing.T) {
+	limiter := newRateLimiter(1, 1, WithRateLimitIdle(time.Second))
+	require.Equal(t, minRateLimitIdle, limiter.buckets.idle)
+
+	// Bucket is kept at least until it is refilled.
+	limiter = newRateLimiter(0.01, 10)
+	require.Equal(t, 1000*time.Second, limiter.buckets.idle)
+}
+
+func TestRateLimitAnswer(t *testing.T) {
+	var answers []string
+
+	client, _ := newTestBot(t, func(writer http.ResponseWriter, req *http.Request) {
+		require.True(t, strings.HasSuffix(req.URL.Path, "/answerCallbackQuery"))
+		require.NoError(t, req.ParseMultipartForm(1<<20))
+
+		answers = append(answers, req.FormValue("text"))
+
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	var handled int
+
+	router := New(WithClient(client))
+	router.Use(RateLimit(1, 1, WithRateLimitHandler(RateLimitAnswer("Slow down"))))
+	router.Callback("data", func(ctx *Context) {
+		handled++
+
+		ctx.Accept()
+	})
+	router.Text("data", func(ctx *Context) {
+		handled++
+
+		ctx.Accept()
+	})
+
+	for range 2 {
+		accepted, err := router.Handle(context.Background(), callbackUpdate("data"))
+		require.NoError(t, err)
+		require.True(t, accepted)
+	}
+
+	require.Equal(t, 1, handled)
+	require.Equal(t, []string{"Slow down"}, answers)
+
+	// Throttled updates without callback query are accepted silently.
+	accepted, err := router.Handle(context.Background(), textUpdate("data"))
+	require.NoError(t, err)
+	require.True(t, accepted)
+	require.Equal(t, 1, handled)
+	require.Len(t, answers, 1)
+}
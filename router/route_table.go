@@ -0,0 +1,106 @@
+package router
+
+import (
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/opoccomaxao/tg-instrumentation/texts"
+	"github.com/pkg/errors"
+)
+
+// route is the result of the route lookup.
+type route struct {
+	handlers []Handler
+	pattern  string
+	names    []string // names of captures, empty for wildcards.
+	captures []string
+}
+
+// routeTable holds routes of all kinds: pattern based, per kind and custom.
+type routeTable struct {
+	patterns map[UpdateKind]*commandList
+	kinds    map[UpdateKind][]Handler
+	custom   customCommandList
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{
+		patterns: map[UpdateKind]*commandList{},
+		kinds:    map[UpdateKind][]Handler{},
+	}
+}
+
+// addPattern panics if the pattern is invalid.
+func (t *routeTable) addPattern(
+	kind UpdateKind,
+	command texts.SimplePattern,
+	normalizer texts.TextReplacer,
+	handler []Handler,
+) {
+	list := t.patterns[kind]
+	if list == nil {
+		list = &commandList{}
+		t.patterns[kind] = list
+	}
+
+	err := list.AddHandler(command, normalizer, handler...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// addRegexp panics if the expression is invalid.
+func (t *routeTable) addRegexp(
+	kind UpdateKind,
+	expr string,
+	normalizer texts.TextReplacer,
+	handler []Handler,
+) {
+	list := t.patterns[kind]
+	if list == nil {
+		list = &commandList{}
+		t.patterns[kind] = list
+	}
+
+	err := list.AddRegexp(expr, normalizer, handler...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// addKind panics if the kind already has a handler.
+func (t *routeTable) addKind(
+	kind UpdateKind,
+	handler []Handler,
+) {
+	if _, ok := t.kinds[kind]; ok {
+		panic(errors.Wrapf(ErrFailed, "handler for %s is already registered", kind))
+	}
+
+	t.kinds[kind] = handler
+}
+
+// find returns handlers of the best matching pattern,
+// then handlers of the kind, then handlers of the first matching custom matcher.
+// Patterns are matched against the text after the normalizer, nil normalizer keeps the text as is.
+func (t *routeTable) find(
+	update *apimodels.Update,
+	kind UpdateKind,
+	text *string,
+	normalizer texts.TextReplacer,
+) (route, bool) {
+	if list := t.patterns[kind]; list != nil && text != nil {
+		res, ok := list.FindHandler(*text, normalizer)
+		if ok {
+			return res, true
+		}
+	}
+
+	if handlers, ok := t.kinds[kind]; ok {
+		return route{handlers: handlers, pattern: kind.String()}, true
+	}
+
+	if handlers, ok := t.custom.FindHandler(update); ok {
+		return route{handlers: handlers, pattern: "?"}, true
+	}
+
+	return route{pattern: "?"}, false
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -11,28 +12,53 @@ import (
 
 	"github.com/go-telegram/bot"
 	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/opoccomaxao/tg-instrumentation/query"
 	"github.com/opoccomaxao/tg-instrumentation/texts"
 	"github.com/pkg/errors"
 )
 
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token" //nolint:gosec
+
 type Router struct {
 	client      *bot.Bot
+	botUsername string
+	normalizer  texts.TextReplacer
+	flood       *FloodLimiter
 	debug       bool
+	secretToken string
 	middlewares []Handler
-	texts       commandList
-	callbacks   commandList
-	inlines     commandList
-	custom      customCommandList
+	routes      *routeTable
+	states      map[string]*routeTable
+	stateStore  StateStore
+	stateKey    StateKeyFunc
 	describer   *texts.CommandDescriber
 	ctxPool     sync.Pool
 	bufferPool  sync.Pool
 	notFound    Handler
+	notFounds   map[UpdateKind]Handler
+	onError     ErrorHandler
+	bindError   BindErrorHandler
+
+	callbackEncoder *query.CallbackEncoder
+	callbackSigner  *query.Signer
+	callbackCodec   query.Codec
 }
 
+// ErrorHandler is called for each error collected by Context.Error during update handling.
+type ErrorHandler func(update *apimodels.Update, pattern string, err error)
+
 func New(opts ...Option) *Router {
 	res := &Router{
-		describer: texts.NewCommandDescriber(),
-		notFound:  AutoAccept(),
+		routes:     newRouteTable(),
+		states:     map[string]*routeTable{},
+		stateStore: NewMemoryStateStore(),
+		stateKey:   StateKeyChatUser,
+		describer:  texts.NewCommandDescriber(),
+		notFound:   AutoAccept(),
+		notFounds:  map[UpdateKind]Handler{},
+		bindError:  DefaultBindErrorHandler,
+
+		callbackCodec: query.TextCodec{},
 	}
 	res.ctxPool.New = res.newContext
 	res.bufferPool.New = func() any {
@@ -62,15 +88,7 @@ func (r *Router) Text(
 	command texts.SimplePattern,
 	handler ...Handler,
 ) TextHandler {
-	err := r.texts.AddHandler(command, handler...)
-	if err != nil {
-		panic(err)
-	}
-
-	return &rawHandler{
-		pattern:   command.String(),
-		describer: r.describer,
-	}
+	return r.root().Text(command, handler...)
 }
 
 // Callback registers a new callback command.
@@ -82,10 +100,7 @@ func (r *Router) Callback(
 	command texts.SimplePattern,
 	handler ...Handler,
 ) {
-	err := r.callbacks.AddHandler(command, handler...)
-	if err != nil {
-		panic(err)
-	}
+	r.root().Callback(command, handler...)
 }
 
 // Inline registers a new inline command.
@@ -97,10 +112,51 @@ func (r *Router) Inline(
 	command texts.SimplePattern,
 	handler ...Handler,
 ) {
-	err := r.inlines.AddHandler(command, handler...)
-	if err != nil {
-		panic(err)
-	}
+	r.root().Inline(command, handler...)
+}
+
+// TextRegexp registers a new text command matched by the regular expression.
+// The expression is not anchored, e.g. `#(?P<tag>\w+)` matches a hashtag anywhere in the text.
+// Groups are available as Context.Param by name, unnamed groups as Context.Wildcards.
+//
+// Regular expressions compete with simple patterns by score:
+// the end of the leftmost match versus the length of the matched prefix.
+// Simple patterns win ties.
+//
+// With WithNormalizer option the expression is matched against the normalized text as is,
+// so it must be written for the normalized form, e.g. in lower case or with (?i) flag.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) TextRegexp(
+	expr string,
+	handler ...Handler,
+) {
+	r.root().TextRegexp(expr, handler...)
+}
+
+// CallbackRegexp registers a new callback command matched by the regular expression.
+// See TextRegexp for the matching rules.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) CallbackRegexp(
+	expr string,
+	handler ...Handler,
+) {
+	r.root().CallbackRegexp(expr, handler...)
+}
+
+// InlineRegexp registers a new inline command matched by the regular expression.
+// See TextRegexp for the matching rules.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) InlineRegexp(
+	expr string,
+	handler ...Handler,
+) {
+	r.root().InlineRegexp(expr, handler...)
 }
 
 // Custom registers a new custom command.
@@ -112,9 +168,31 @@ func (r *Router) Custom(
 	matcher UpdateMatcher,
 	handler ...Handler,
 ) {
-	r.custom.AddHandler(matcher, handler...)
+	r.root().Custom(matcher, handler...)
+}
+
+// Normalized returns a group matching its routes against the normalized text.
+// See Group.Normalized and WithNormalizer for router-wide normalization.
+func (r *Router) Normalized(normalizer texts.TextReplacer) *Group {
+	return r.root().Normalized(normalizer)
 }
 
+// root returns the group registering routes in the router without prefix and middlewares.
+func (r *Router) root() *Group {
+	return &Group{
+		router: r,
+		table:  r.routes,
+	}
+}
+
+// OnError sets the hook called for each error collected while handling an update.
+// The same errors are returned from Handle as a joined error.
+func (r *Router) OnError(handler ErrorHandler) {
+	r.onError = handler
+}
+
+// NotFound sets the handler for updates without matching route.
+// It is used for kinds without own handler set by NotFoundKind.
 func (r *Router) NotFound(handler Handler) {
 	if handler == nil {
 		handler = AutoAccept()
@@ -123,6 +201,26 @@ func (r *Router) NotFound(handler Handler) {
 	r.notFound = handler
 }
 
+// NotFoundKind sets the handler for updates of the kind without matching route.
+// Nil handler resets the kind to the NotFound handler.
+func (r *Router) NotFoundKind(kind UpdateKind, handler Handler) {
+	if handler == nil {
+		delete(r.notFounds, kind)
+
+		return
+	}
+
+	r.notFounds[kind] = handler
+}
+
+func (r *Router) notFoundHandler(kind UpdateKind) Handler {
+	if handler, ok := r.notFounds[kind]; ok {
+		return handler
+	}
+
+	return r.notFound
+}
+
 func (r *Router) ListCommandsParams() []*apimodels.SetMyCommandsParams {
 	return r.describer.ListCommandsParams()
 }
@@ -155,47 +253,128 @@ func (r *Router) putBuffer(b *bytes.Buffer) {
 	r.bufferPool.Put(b)
 }
 
+// expandCallbackData replaces the callback data token with the original data.
+// Updates with expired tokens are handled with the token as is.
+func (r *Router) expandCallbackData(ctx *Context) {
+	if r.callbackEncoder == nil || ctx.kind != KindCallbackQuery {
+		return
+	}
+
+	data, err := r.callbackEncoder.Expand(ctx.ctx, *ctx.text)
+	if err != nil {
+		ctx.Error(err)
+
+		return
+	}
+
+	ctx.text = &data
+}
+
+// verifyCallbackData strips the signature from the callback data.
+// Data without a well-formed signature is kept as is.
+// Verification error is kept in the context for SignedCallbacks middleware,
+// the update is matched by the data without signature anyway.
+func (r *Router) verifyCallbackData(ctx *Context) {
+	if ctx.kind != KindCallbackQuery || ctx.text == nil {
+		return
+	}
+
+	if r.callbackSigner == nil {
+		ctx.signatureErr = errors.Wrap(query.ErrInvalidSignature, "signer is not set. use router.WithCallbackSigner() option")
+
+		return
+	}
+
+	data, err := r.callbackSigner.Verify(*ctx.text)
+	ctx.signatureErr = err
+	ctx.text = &data
+}
+
+// decodeCallbackData converts the compact callback data to the text form used for matching.
+// Data that is not in the exact compact form is kept byte-for-byte.
+func (r *Router) decodeCallbackData(ctx *Context) {
+	if ctx.kind != KindCallbackQuery || ctx.text == nil || !query.IsCompact(*ctx.text) {
+		return
+	}
+
+	decode := query.DecodeCompact
+	if decoder, ok := r.callbackCodec.(query.CompactDecoder); ok {
+		decode = decoder.DecodeCompact
+	}
+
+	q, err := decode(*ctx.text)
+	if err != nil {
+		return
+	}
+
+	data := q.Encode()
+	ctx.text = &data
+}
+
+// textNormalizer returns the normalizer of WithNormalizer for texts typed by a user.
+// The text is normalized only for matching, the context keeps it as is.
+func (r *Router) textNormalizer(kind UpdateKind) texts.TextReplacer {
+	if !isNormalizedKind(kind) {
+		return nil
+	}
+
+	return r.normalizer
+}
+
+// findHandlers looks for the routes of the current state first, then for the common routes.
+func (r *Router) findHandlers(ctx *Context) (route, bool) {
+	if len(r.states) > 0 {
+		if table := r.states[ctx.State()]; table != nil {
+			res, ok := table.find(ctx.update, ctx.kind, ctx.text, r.textNormalizer(ctx.kind))
+			if ok {
+				return res, true
+			}
+		}
+	}
+
+	return r.routes.find(ctx.update, ctx.kind, ctx.text, r.textNormalizer(ctx.kind))
+}
+
+// Handle routes the update to the matching handlers.
+// Returns true if the update was accepted and the errors collected by handlers joined into one.
 func (r *Router) Handle(
 	ctx context.Context,
 	update *apimodels.Update,
 	opts ...ContextOption,
 ) (bool, error) {
+	rCtx := r.getContext()
+	defer r.putContext(rCtx)
+
+	rCtx.ctx = ctx
+	rCtx.update = update
+	rCtx.kind = UpdateKindOf(update)
+	rCtx.text = updateText(update)
+
+	r.expandCallbackData(rCtx)
+	r.verifyCallbackData(rCtx)
+	r.decodeCallbackData(rCtx)
+
 	var (
-		ok       bool
-		pattern  string
-		handlers []Handler
-		text     *string
+		found route
+		ok    bool
 	)
 
-	switch {
-	case update.Message != nil:
-		handlers, pattern, ok = r.texts.FindHandler(update.Message.Text)
-		text = &update.Message.Text
-	case update.CallbackQuery != nil:
-		handlers, pattern, ok = r.callbacks.FindHandler(update.CallbackQuery.Data)
-		text = &update.CallbackQuery.Data
-	case update.InlineQuery != nil:
-		handlers, pattern, ok = r.inlines.FindHandler(update.InlineQuery.Query)
-		text = &update.InlineQuery.Query
+	if r.stripBotUsername(rCtx) {
+		found, ok = r.findHandlers(rCtx)
+	} else {
+		// Commands addressed to other bots are not routed.
+		found, ok = route{pattern: ignoredPattern, handlers: []Handler{AutoAccept()}}, true
 	}
 
 	if !ok {
-		handlers, ok = r.custom.FindHandler(update)
-		pattern = "?"
+		found.handlers = []Handler{r.notFoundHandler(rCtx.kind)}
 	}
 
-	if !ok {
-		handlers = []Handler{r.notFound}
-	}
-
-	rCtx := r.getContext()
-	defer r.putContext(rCtx)
-
-	rCtx.ctx = ctx
-	rCtx.update = update
-	rCtx.text = text
-	rCtx.pattern = pattern
-	rCtx.handlers = slices.Concat(r.middlewares, handlers)
+	rCtx.pattern = found.pattern
+	rCtx.notFound = !ok
+	rCtx.handlers = slices.Concat(r.middlewares, found.handlers)
+	rCtx.paramNames = found.names
+	rCtx.params = found.captures
 
 	for _, opt := range opts {
 		opt(rCtx)
@@ -203,31 +382,61 @@ func (r *Router) Handle(
 
 	rCtx.Next()
 
-	return rCtx.IsAccepted(), nil
+	errs := rCtx.Errors()
+	if r.onError != nil {
+		for _, err := range errs {
+			r.onError(update, found.pattern, err)
+		}
+	}
+
+	return rCtx.IsAccepted(), joinErrors(errs)
 }
 
 // HandlerFunc is Webhook handler as http.HandlerFunc implementation.
 func (r *Router) HandlerFunc(
 	writer http.ResponseWriter,
 	req *http.Request,
+) {
+	r.serveWebhook(r, writer, req)
+}
+
+// WebhookHandler returns Webhook handler that passes decoded updates to the handler,
+// e.g. to Dispatcher. Secret token and debug options of the router are applied.
+func (r *Router) WebhookHandler(handler UpdateHandler) http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		r.serveWebhook(handler, writer, req)
+	}
+}
+
+func (r *Router) serveWebhook(
+	handler UpdateHandler,
+	writer http.ResponseWriter,
+	req *http.Request,
 ) {
 	defer req.Body.Close()
 
+	if !r.checkSecretToken(req) {
+		http.Error(writer, "invalid secret token", http.StatusUnauthorized)
+
+		return
+	}
+
 	var (
 		update apimodels.Update
 		opts   []ContextOption
+		raw    *bytes.Buffer
 	)
 
 	reader := io.Reader(req.Body)
 
 	if r.debug {
-		buf := r.getBuffer()
-		defer r.putBuffer(buf)
+		raw = r.getBuffer()
+		defer r.putBuffer(raw)
 
-		reader = io.TeeReader(reader, buf)
+		reader = io.TeeReader(reader, raw)
 
 		opts = append(opts, func(ctx *Context) {
-			ctx.raw = buf
+			ctx.raw = raw
 		})
 	}
 
@@ -238,8 +447,14 @@ func (r *Router) HandlerFunc(
 		return
 	}
 
-	accepted, err := r.Handle(req.Context(), &update, opts...)
-	if err != nil {
+	// Asynchronous handlers may outlive the pooled buffer.
+	if raw != nil && handler != UpdateHandler(r) {
+		raw = bytes.NewBuffer(bytes.Clone(raw.Bytes()))
+	}
+
+	accepted, err := handler.Handle(req.Context(), &update, opts...)
+	// Accepted update must not be redelivered, even if some handlers failed.
+	if err != nil && !accepted {
 		http.Error(writer, err.Error(), http.StatusInternalServerError)
 
 		return
@@ -252,6 +467,41 @@ func (r *Router) HandlerFunc(
 	}
 }
 
+func (r *Router) checkSecretToken(req *http.Request) bool {
+	if r.secretToken == "" {
+		return true
+	}
+
+	return subtle.ConstantTimeCompare(
+		[]byte(req.Header.Get(secretTokenHeader)),
+		[]byte(r.secretToken),
+	) == 1
+}
+
+// SetWebhook registers the webhook in Telegram API.
+// The secret token from WithSecretToken option is used unless params already contain one.
+// The params are not modified.
+func (r *Router) SetWebhook(
+	ctx context.Context,
+	params *bot.SetWebhookParams,
+) error {
+	if r.client == nil {
+		return errors.Wrap(ErrFailed, "client is not set. use router.New() with router.WithClient() option")
+	}
+
+	request := *params
+	if request.SecretToken == "" {
+		request.SecretToken = r.secretToken
+	}
+
+	_, err := r.client.SetWebhook(ctx, &request)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
 // UpdateCommandsDescription sends all registered commands to Telegram API.
 func (r *Router) UpdateCommandsDescription(
 	ctx context.Context,
@@ -0,0 +1,148 @@
+package router
+
+import "github.com/opoccomaxao/tg-instrumentation/texts"
+
+// EditedMessage registers a new handler for edited messages.
+// The command is a simple pattern matched against the message text.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) EditedMessage(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	r.root().EditedMessage(command, handler...)
+}
+
+// ChannelPost registers a new handler for channel posts.
+// The command is a simple pattern matched against the post text.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) ChannelPost(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	r.root().ChannelPost(command, handler...)
+}
+
+// EditedChannelPost registers a new handler for edited channel posts.
+// The command is a simple pattern matched against the post text.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) EditedChannelPost(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	r.root().EditedChannelPost(command, handler...)
+}
+
+// BusinessMessage registers a new handler for messages from connected business accounts.
+// The command is a simple pattern matched against the message text.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) BusinessMessage(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	r.root().BusinessMessage(command, handler...)
+}
+
+// EditedBusinessMessage registers a new handler for edited messages from connected business accounts.
+// The command is a simple pattern matched against the message text.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) EditedBusinessMessage(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	r.root().EditedBusinessMessage(command, handler...)
+}
+
+// ChosenInlineResult registers a new handler for chosen inline results.
+// The command is a simple pattern matched against the inline query.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) ChosenInlineResult(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	r.root().ChosenInlineResult(command, handler...)
+}
+
+// ShippingQuery registers a new handler for shipping queries.
+// The command is a simple pattern matched against the invoice payload.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) ShippingQuery(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	r.root().ShippingQuery(command, handler...)
+}
+
+// PreCheckoutQuery registers a new handler for pre-checkout queries.
+// The command is a simple pattern matched against the invoice payload.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if an error occurs.
+func (r *Router) PreCheckoutQuery(
+	command texts.SimplePattern,
+	handler ...Handler,
+) {
+	r.root().PreCheckoutQuery(command, handler...)
+}
+
+// ChatMember registers a handler for chat member status updates.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if the handler is already registered.
+func (r *Router) ChatMember(handler ...Handler) {
+	r.root().ChatMember(handler...)
+}
+
+// MyChatMember registers a handler for the bot's own chat member status updates.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if the handler is already registered.
+func (r *Router) MyChatMember(handler ...Handler) {
+	r.root().MyChatMember(handler...)
+}
+
+// ChatJoinRequest registers a handler for chat join requests.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if the handler is already registered.
+func (r *Router) ChatJoinRequest(handler ...Handler) {
+	r.root().ChatJoinRequest(handler...)
+}
+
+// PollAnswer registers a handler for poll answers.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if the handler is already registered.
+func (r *Router) PollAnswer(handler ...Handler) {
+	r.root().PollAnswer(handler...)
+}
+
+// MessageReaction registers a handler for message reaction changes.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if the handler is already registered.
+func (r *Router) MessageReaction(handler ...Handler) {
+	r.root().MessageReaction(handler...)
+}
+
+// Kind registers a handler for any update kind, e.g. KindPoll or KindChatBoost.
+// For kinds with text prefer pattern based methods, they take precedence over this handler.
+//
+// WARNING: this method must be called in the initialization phase.
+// It panics if the handler is already registered.
+func (r *Router) Kind(kind UpdateKind, handler ...Handler) {
+	r.root().Kind(kind, handler...)
+}
@@ -0,0 +1,163 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_kinds(t *testing.T) {
+	testCases := []struct {
+		name    string
+		update  *apimodels.Update
+		kind    UpdateKind
+		pattern string
+	}{
+		{
+			name:    "chat member",
+			update:  &apimodels.Update{ChatMember: &models.ChatMemberUpdated{}},
+			kind:    KindChatMember,
+			pattern: string(KindChatMember),
+		},
+		{
+			name:    "my chat member",
+			update:  &apimodels.Update{MyChatMember: &models.ChatMemberUpdated{}},
+			kind:    KindMyChatMember,
+			pattern: string(KindMyChatMember),
+		},
+		{
+			name:    "poll answer",
+			update:  &apimodels.Update{PollAnswer: &models.PollAnswer{}},
+			kind:    KindPollAnswer,
+			pattern: string(KindPollAnswer),
+		},
+		{
+			name:    "poll",
+			update:  &apimodels.Update{Poll: &models.Poll{}},
+			kind:    KindPoll,
+			pattern: string(KindPoll),
+		},
+		{
+			name:    "edited message",
+			update:  &apimodels.Update{EditedMessage: &models.Message{Text: "/edit"}},
+			kind:    KindEditedMessage,
+			pattern: "/edit",
+		},
+		{
+			name:    "channel post",
+			update:  &apimodels.Update{ChannelPost: &models.Message{Text: "/post"}},
+			kind:    KindChannelPost,
+			pattern: "/post",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				kind    UpdateKind
+				pattern string
+			)
+
+			record := func(ctx *Context) {
+				ctx.Accept()
+
+				kind = ctx.Kind()
+				pattern = ctx.Pattern()
+			}
+
+			router := New()
+			router.NotFound(func(*Context) {
+				require.Fail(t, "not found")
+			})
+			router.Text("/edit", func(*Context) {
+				require.Fail(t, "message route")
+			})
+			router.ChatMember(record)
+			router.MyChatMember(record)
+			router.PollAnswer(record)
+			router.Kind(KindPoll, record)
+			router.EditedMessage("/edit", record)
+			router.ChannelPost("/post", record)
+
+			accepted, err := router.Handle(context.Background(), tc.update)
+			require.NoError(t, err)
+			require.True(t, accepted)
+			require.Equal(t, tc.kind, kind)
+			require.Equal(t, tc.pattern, pattern)
+		})
+	}
+}
+
+func TestRouter_NotFoundKind(t *testing.T) {
+	testCases := []struct {
+		name    string
+		update  *apimodels.Update
+		reset   bool
+		handler string
+	}{
+		{
+			name:    "kind override",
+			update:  callbackUpdate("missing"),
+			handler: "callback",
+		},
+		{
+			name:    "other kind",
+			update:  textUpdate("/missing"),
+			handler: "common",
+		},
+		{
+			name:    "reset",
+			update:  callbackUpdate("missing"),
+			reset:   true,
+			handler: "common",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handler string
+
+			router := New()
+			router.NotFound(func(*Context) {
+				handler = "common"
+			})
+			router.NotFoundKind(KindCallbackQuery, func(*Context) {
+				handler = "callback"
+			})
+
+			if tc.reset {
+				router.NotFoundKind(KindCallbackQuery, nil)
+			}
+
+			accepted, err := router.Handle(context.Background(), tc.update)
+			require.NoError(t, err)
+			require.False(t, accepted)
+			require.Equal(t, tc.handler, handler)
+		})
+	}
+}
+
+func TestRouter_kindTwice(t *testing.T) {
+	testCases := []struct {
+		name     string
+		register func(*Router)
+	}{
+		{name: "chat member", register: func(r *Router) { r.ChatMember(AutoAccept()) }},
+		{name: "poll answer", register: func(r *Router) { r.PollAnswer(AutoAccept()) }},
+		{name: "kind", register: func(r *Router) { r.Kind(KindPoll, AutoAccept()) }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := New()
+			tc.register(router)
+
+			require.Panics(t, func() {
+				tc.register(router)
+			})
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package router
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/opoccomaxao/tg-instrumentation/query"
+	"github.com/opoccomaxao/tg-instrumentation/storage"
+	"github.com/opoccomaxao/tg-instrumentation/texts"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_HandleErrors(t *testing.T) {
+	var (
+		first  = errors.New("first")
+		second = errors.New("second")
+	)
+
+	type reported struct {
+		pattern string
+		err     error
+	}
+
+	testCases := []struct {
+		name     string
+		text     string
+		accepted bool
+		errs     []error
+		reported []reported
+	}{
+		{
+			name:     "joined errors",
+			text:     "/fail 1",
+			accepted: true,
+			errs:     []error{first, second},
+			reported: []reported{
+				{pattern: "/fail :id", err: first},
+				{pattern: "/fail :id", err: second},
+			},
+		},
+		{
+			name:     "no errors",
+			text:     "/ok",
+			accepted: true,
+		},
+		{
+			name:     "not found",
+			text:     "/missing",
+			accepted: false,
+			errs:     []error{first},
+			reported: []reported{
+				{pattern: "?", err: first},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []reported
+
+			router := New()
+			router.OnError(func(_ *apimodels.Update, pattern string, err error) {
+				got = append(got, reported{pattern: pattern, err: err})
+			})
+			router.NotFound(func(ctx *Context) {
+				ctx.Error(first)
+			})
+			router.Text("/fail :id", func(ctx *Context) {
+				ctx.Error(first)
+				ctx.Next()
+			}, func(ctx *Context) {
+				ctx.Accept()
+				ctx.Error(second)
+			})
+			router.Text("/ok", func(ctx *Context) {
+				ctx.Accept()
+			})
+
+			accepted, err := router.Handle(context.Background(), textUpdate(tc.text))
+			require.Equal(t, tc.accepted, accepted)
+			require.Equal(t, tc.reported, got)
+
+			if len(tc.errs) == 0 {
+				require.NoError(t, err)
+
+				return
+			}
+
+			for _, expected := range tc.errs {
+				require.ErrorIs(t, err, expected)
+			}
+		})
+	}
+}
+
+func TestRouter_callbackSignature(t *testing.T) {
+	signer := query.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		name   string
+		data   string
+		text   string
+		signed bool
+	}{
+		{name: "signed", data: signer.Sign(`search q="wow !nice"`), text: `search q="wow !nice"`, signed: true},
+		{name: "unsigned with delimiter", data: `search q="wow !nice"`, text: `search q="wow !nice"`},
+		{name: "tampered", data: "search" + signer.Sign("other")[len("other"):], text: "search"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				text   string
+				sigErr error
+			)
+
+			router := New(WithCallbackSigner(signer))
+			router.Callback("search", func(ctx *Context) {
+				text, _ = ctx.Text()
+				sigErr = ctx.SignatureError()
+
+				ctx.Accept()
+			})
+
+			accepted, err := router.Handle(context.Background(), callbackUpdate(tc.data))
+			require.NoError(t, err)
+			require.True(t, accepted)
+			require.Equal(t, tc.text, text)
+
+			if tc.signed {
+				require.NoError(t, sigErr)
+			} else {
+				require.ErrorIs(t, sigErr, query.ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestRouter_normalizer(t *testing.T) {
+	type result struct {
+		pattern string
+		text    string
+		params  []string
+		query   string
+	}
+
+	testCases := []struct {
+		name   string
+		update *apimodels.Update
+		result result
+	}{
+		{
+			name:   "params keep case",
+			update: textUpdate("/Article 42 Q=Hello"),
+			result: result{pattern: "/article :id", text: "/Article 42 Q=Hello", params: []string{"42"}, query: "Hello"},
+		},
+		{
+			name:   "wildcard keeps spaces",
+			update: textUpdate("  /ＮＡＭＥ   Alice  Smith "),
+			result: result{pattern: "/name *", text: "  /ＮＡＭＥ   Alice  Smith ", params: []string{"Alice  Smith"}},
+		},
+		{
+			name:   "regexp",
+			update: textUpdate("Nice #GoLang"),
+			result: result{pattern: `#(?P<tag>\w+)`, text: "Nice #GoLang", params: []string{"GoLang"}},
+		},
+		{
+			name:   "case-insensitive regexp",
+			update: textUpdate("/SMALL 5"),
+			result: result{pattern: `(?i)^/small (\d+)`, text: "/SMALL 5", params: []string{"5"}},
+		},
+		{
+			name:   "regexp is not normalized",
+			update: textUpdate("/Big 5"),
+			result: result{pattern: "?", text: "/Big 5"},
+		},
+		{
+			name:   "bot username",
+			update: textUpdate("/ARTICLE@mybot 7"),
+			result: result{pattern: "/article :id", text: "/ARTICLE 7", params: []string{"7"}},
+		},
+		{
+			name:   "other bot",
+			update: textUpdate("/ARTICLE@OtherBot 7"),
+			result: result{pattern: ignoredPattern, text: "/ARTICLE@OtherBot 7"},
+		},
+		{
+			name:   "callback is not normalized",
+			update: callbackUpdate("/Article 1"),
+			result: result{pattern: "?", text: "/Article 1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var res result
+
+			record := func(ctx *Context) {
+				res.query, _ = ctx.Query().Get("Q")
+				res.params = slices.Clone(ctx.params)
+
+				ctx.Accept()
+			}
+
+			router := New(
+				WithNormalizer(texts.NewNormalizer(texts.NormalizeAll)),
+				WithBotUsername("MyBot"),
+			)
+			router.Use(func(ctx *Context) {
+				res.pattern = ctx.Pattern()
+				res.text, _ = ctx.Text()
+
+				ctx.Next()
+			})
+			router.NotFound(record)
+			router.Text("/Article :id", record)
+			router.Text("/Name *", record)
+			router.TextRegexp(`#(?P<tag>\w+)`, record)
+			router.TextRegexp(`(?i)^/small (\d+)`, record)
+			router.TextRegexp(`^/Big (\d+)`, record)
+			router.Callback("/article :id", record)
+
+			accepted, err := router.Handle(context.Background(), tc.update)
+			require.NoError(t, err)
+			require.True(t, accepted)
+			require.Equal(t, tc.result, res)
+		})
+	}
+}
+
+func TestGroup_Normalized(t *testing.T) {
+	var (
+		pattern string
+		params  []string
+		text    string
+	)
+
+	router := New()
+	router.Normalized(texts.NewNormalizer(texts.NormalizeCase)).Text("/Ban :user", func(ctx *Context) {
+		pattern = ctx.Pattern()
+		params = []string{ctx.Param("user")}
+		text, _ = ctx.Text()
+
+		ctx.Accept()
+	})
+	router.Text("/Ban", func(*Context) {
+		require.FailNow(t, "not normalized route must not match")
+	})
+
+	accepted, err := router.Handle(context.Background(), textUpdate("/BAN Straße"))
+	require.NoError(t, err)
+	require.True(t, accepted)
+	require.Equal(t, "/ban :user", pattern)
+	require.Equal(t, []string{"Straße"}, params)
+	require.Equal(t, "/BAN Straße", text)
+}
+
+func TestRouter_callbackToken(t *testing.T) {
+	long := strings.Repeat("a", query.MaxCallbackDataSize)
+
+	testCases := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "text"},
+		{name: "signed", opts: []Option{WithCallbackSigner(query.NewSigner([]byte("secret")))}},
+		{name: "compact", opts: []Option{WithCallbackCodec(query.NewCompactCodec())}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				token string
+				text  string
+			)
+
+			encoder := query.NewCallbackEncoder(storage.NewMemory())
+
+			router := New(append(tc.opts, WithCallbackEncoder(encoder))...)
+			router.Text("/menu", func(ctx *Context) {
+				ctx.Accept()
+
+				var err error
+
+				token, err = ctx.EncodeCallback(query.Command("long").WithParam("text", long))
+				require.NoError(t, err)
+			})
+			router.Callback("long", func(ctx *Context) {
+				ctx.Accept()
+
+				text, _ = ctx.Query().Get("text")
+			})
+
+			_, err := router.Handle(context.Background(), textUpdate("/menu"))
+			require.NoError(t, err)
+			require.True(t, encoder.IsToken(token))
+
+			accepted, err := router.Handle(context.Background(), callbackUpdate(token))
+			require.NoError(t, err)
+			require.True(t, accepted)
+			require.Equal(t, long, text)
+		})
+	}
+}
+
+func TestRouter_callbackTokenExpired(t *testing.T) {
+	notFound := false
+
+	router := New(WithCallbackEncoder(query.NewCallbackEncoder(storage.NewMemory())))
+	router.NotFound(func(*Context) {
+		notFound = true
+	})
+	router.Callback("long", func(*Context) {
+		require.Fail(t, "unknown token is routed")
+	})
+
+	accepted, err := router.Handle(context.Background(), callbackUpdate("~unknown"))
+	require.ErrorIs(t, err, query.ErrExpired)
+	require.False(t, accepted)
+	require.True(t, notFound)
+}
+
+func TestRouter_compactCallback(t *testing.T) {
+	aliases := query.NewCompactCodec(query.WithKeyAliases("article"))
+	aliased := aliases.Encode(query.Command("article").WithParamInt64("id", 1))
+	plain := query.NewCompactCodec().Encode(query.Command("menu").WithParamInt64("page", 2))
+
+	testCases := []struct {
+		name  string
+		codec query.Codec
+		data  string
+		text  string
+	}{
+		{name: "compact", codec: query.TextCodec{}, data: plain, text: "menu page=2"},
+		{name: "compact with aliases", codec: aliases, data: aliased, text: "article id=1"},
+		{name: "not compact", codec: query.TextCodec{}, data: "-c b a", text: "-c b a"},
+		{name: "not compact with compact codec", codec: aliases, data: "-c b a", text: "-c b a"},
+		{name: "not canonical", codec: aliases, data: plain + "A", text: plain + "A"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var text string
+
+			router := New(WithCallbackCodec(tc.codec))
+			router.Use(func(ctx *Context) {
+				text, _ = ctx.Text()
+			})
+
+			_, err := router.Handle(context.Background(), callbackUpdate(tc.data))
+			require.NoError(t, err)
+			require.Equal(t, tc.text, text)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/opoccomaxao/tg-instrumentation/storage"
+	"github.com/pkg/errors"
+)
+
+const (
+	sessionKeyPrefix = "session:"
+	stateKeyPrefix   = "state:"
+)
+
+// Session is the data kept between updates of the same conversation.
+// Values are stored as JSON.
+type Session struct {
+	data  map[string]json.RawMessage
+	dirty bool
+}
+
+func newSession() *Session {
+	return &Session{
+		data: map[string]json.RawMessage{},
+	}
+}
+
+// Has returns true if the key is set.
+func (s *Session) Has(key string) bool {
+	_, ok := s.data[key]
+
+	return ok
+}
+
+// Get decodes the value into the pointer. Returns false if the key is missing or cannot be decoded.
+func (s *Session) Get(key string, into any) bool {
+	raw, ok := s.data[key]
+	if !ok {
+		return false
+	}
+
+	return json.Unmarshal(raw, into) == nil
+}
+
+// Set encodes and stores the value.
+func (s *Session) Set(key string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	s.data[key] = raw
+	s.dirty = true
+
+	return nil
+}
+
+// Delete removes the key.
+func (s *Session) Delete(key string) {
+	if _, ok := s.data[key]; !ok {
+		return
+	}
+
+	delete(s.data, key)
+
+	s.dirty = true
+}
+
+// Clear removes all keys.
+func (s *Session) Clear() {
+	if len(s.data) == 0 {
+		return
+	}
+
+	clear(s.data)
+
+	s.dirty = true
+}
+
+// SessionValue returns the typed value from the session.
+func SessionValue[T any](s *Session, key string) (T, bool) {
+	var res T
+
+	ok := s.Get(key, &res)
+
+	return res, ok
+}
+
+type sessionConfig struct {
+	keyFunc StateKeyFunc
+	ttl     time.Duration
+}
+
+type SessionOption func(*sessionConfig)
+
+// WithSessionKey sets how updates are grouped into sessions. Default is StateKeyChatUser.
+func WithSessionKey(keyFunc StateKeyFunc) SessionOption {
+	return func(c *sessionConfig) {
+		c.keyFunc = keyFunc
+	}
+}
+
+// WithSessionTTL sets the session lifetime since the last change. Default is no expiration.
+func WithSessionTTL(ttl time.Duration) SessionOption {
+	return func(c *sessionConfig) {
+		c.ttl = ttl
+	}
+}
+
+// Sessions loads the session before the chain and saves it after the chain if it was changed.
+// Use Context.Session to access it.
+func Sessions(store storage.Storage, opts ...SessionOption) Handler {
+	cfg := sessionConfig{
+		keyFunc: StateKeyChatUser,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(ctx *Context) {
+		key, ok := cfg.keyFunc(ctx.Update())
+		if !ok {
+			ctx.Next()
+
+			return
+		}
+
+		key = sessionKeyPrefix + key
+
+		session, err := loadSession(ctx.Context(), store, key)
+		if err != nil {
+			ctx.Error(err)
+			ctx.Abort()
+
+			return
+		}
+
+		ctx.session = session
+
+		ctx.Next()
+
+		if !session.dirty {
+			return
+		}
+
+		ctx.LogError1(saveSession(context.WithoutCancel(ctx.Context()), store, key, session, cfg.ttl))
+	}
+}
+
+func loadSession(ctx context.Context, store storage.Storage, key string) (*Session, error) {
+	res := newSession()
+
+	raw, ok, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "load session")
+	}
+
+	if !ok {
+		return res, nil
+	}
+
+	err = json.Unmarshal(raw, &res.data)
+	if err != nil {
+		return nil, errors.Wrapf(ErrFailed, "decode session: %v", err)
+	}
+
+	return res, nil
+}
+
+func saveSession(
+	ctx context.Context,
+	store storage.Storage,
+	key string,
+	session *Session,
+	ttl time.Duration,
+) error {
+	if len(session.data) == 0 {
+		return errors.WithMessage(store.Delete(ctx, key), "save session")
+	}
+
+	raw, err := json.Marshal(session.data)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithMessage(store.Set(ctx, key, raw, ttl), "save session")
+}
+
+// Session returns the session loaded by the Sessions middleware.
+// Without the middleware it returns an empty session that is not saved.
+func (c *Context) Session() *Session {
+	if c.session == nil {
+		c.session = newSession()
+	}
+
+	return c.session
+}
+
+// StorageStateStore keeps conversation states in the storage.
+type StorageStateStore struct {
+	store storage.Storage
+	ttl   time.Duration
+}
+
+// NewStorageStateStore creates a state store over the storage.
+// Zero ttl means states never expire.
+func NewStorageStateStore(store storage.Storage, ttl time.Duration) *StorageStateStore {
+	return &StorageStateStore{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+func (s *StorageStateStore) GetState(ctx context.Context, key string) (string, error) {
+	raw, _, err := s.store.Get(ctx, stateKeyPrefix+key)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return string(raw), nil
+}
+
+func (s *StorageStateStore) SetState(ctx context.Context, key string, state string) error {
+	if state == "" {
+		return errors.WithStack(s.store.Delete(ctx, stateKeyPrefix+key))
+	}
+
+	return errors.WithStack(s.store.Set(ctx, stateKeyPrefix+key, []byte(state), s.ttl))
+}
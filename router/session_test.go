@@ -0,0 +1,145 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opoccomaxao/tg-instrumentation/storage"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStorage counts writes and fails reads with getErr.
+type recordingStorage struct {
+	*storage.Memory
+
+	getErr  error
+	sets    int
+	deletes int
+}
+
+func (s *recordingStorage) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if s.getErr != nil {
+		return nil, false, s.getErr
+	}
+
+	//nolint:wrapcheck
+	return s.Memory.Get(ctx, key)
+}
+
+func (s *recordingStorage) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.sets++
+
+	//nolint:wrapcheck
+	return s.Memory.Set(ctx, key, value, ttl)
+}
+
+func (s *recordingStorage) Delete(ctx context.Context, key string) error {
+	s.deletes++
+
+	//nolint:wrapcheck
+	return s.Memory.Delete(ctx, key)
+}
+
+func TestSessions(t *testing.T) {
+	type step struct {
+		text    string
+		value   string
+		found   bool
+		sets    int
+		deletes int
+	}
+
+	testCases := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "value is visible in the next update",
+			steps: []step{
+				{text: "/set alice", sets: 1},
+				{text: "/get", value: "alice", found: true, sets: 1},
+			},
+		},
+		{
+			name: "unmodified session is not written",
+			steps: []step{
+				{text: "/get"},
+				{text: "/set alice", sets: 1},
+				{text: "/get", value: "alice", found: true, sets: 1},
+				{text: "/get", value: "alice", found: true, sets: 1},
+			},
+		},
+		{
+			name: "emptied session is deleted",
+			steps: []step{
+				{text: "/set alice", sets: 1},
+				{text: "/clear", sets: 1, deletes: 1},
+				{text: "/get", sets: 1, deletes: 1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				value string
+				found bool
+			)
+
+			store := &recordingStorage{Memory: storage.NewMemory()}
+
+			router := New()
+			router.Use(Sessions(store))
+			router.Text("/set :name", func(ctx *Context) {
+				ctx.Accept()
+				require.NoError(t, ctx.Session().Set("name", ctx.Param("name")))
+			})
+			router.Text("/get", func(ctx *Context) {
+				ctx.Accept()
+
+				value, found = SessionValue[string](ctx.Session(), "name")
+			})
+			router.Text("/clear", func(ctx *Context) {
+				ctx.Accept()
+				ctx.Session().Clear()
+			})
+
+			for i, step := range tc.steps {
+				value, found = "", false
+
+				_, err := router.Handle(context.Background(), textUpdate(step.text))
+				require.NoError(t, err, i)
+				require.Equal(t, step.value, value, i)
+				require.Equal(t, step.found, found, i)
+				require.Equal(t, step.sets, store.sets, i)
+				require.Equal(t, step.deletes, store.deletes, i)
+			}
+		})
+	}
+}
+
+func TestSessions_loadError(t *testing.T) {
+	loadErr := errors.New("load failed")
+	called := false
+
+	store := &recordingStorage{
+		Memory: storage.NewMemory(),
+		getErr: loadErr,
+	}
+
+	router := New()
+	router.Use(Sessions(store))
+	router.Text("/get", func(ctx *Context) {
+		ctx.Accept()
+
+		called = true
+	})
+
+	accepted, err := router.Handle(context.Background(), textUpdate("/get"))
+	require.ErrorIs(t, err, loadErr)
+	require.False(t, accepted)
+	require.False(t, called)
+	require.Zero(t, store.sets)
+}
@@ -0,0 +1,50 @@
+package router
+
+type SignedCallbacksOption func(*signedCallbacks)
+
+// WithSignedCallbacksHandler sets the handler called instead of the chain for rejected callback queries.
+// The verification error is available as Context.SignatureError.
+// Default handler accepts the update silently.
+func WithSignedCallbacksHandler(handler Handler) SignedCallbacksOption {
+	return func(s *signedCallbacks) {
+		s.rejected = handler
+	}
+}
+
+type signedCallbacks struct {
+	rejected Handler
+}
+
+// SignedCallbacks rejects callback queries with missing, invalid or expired signatures.
+// Other updates are passed to the chain. It requires WithCallbackSigner option,
+// without it all callback queries are rejected.
+//
+// Example:
+//
+//	r := router.New(router.WithCallbackSigner(query.NewSigner(key, query.WithSignatureTTL(24*time.Hour))))
+//	r.Use(router.SignedCallbacks())
+func SignedCallbacks(opts ...SignedCallbacksOption) Handler {
+	res := &signedCallbacks{
+		rejected: AutoAccept(),
+	}
+
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res.handle
+}
+
+func (s *signedCallbacks) handle(ctx *Context) {
+	if ctx.SignatureError() != nil {
+		ctx.Abort()
+
+		if s.rejected != nil {
+			s.rejected(ctx)
+		}
+
+		return
+	}
+
+	ctx.Next()
+}
@@ -0,0 +1,117 @@
+package router
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+)
+
+// StateStore keeps the conversation state by key.
+type StateStore interface {
+	// GetState returns the state or empty string if it is not set.
+	GetState(ctx context.Context, key string) (string, error)
+	// SetState sets the state. Empty state removes the key.
+	SetState(ctx context.Context, key string, state string) error
+}
+
+// StateKeyFunc returns the key of the conversation the update belongs to.
+// Updates without a key have no state.
+type StateKeyFunc func(update *apimodels.Update) (string, bool)
+
+// StateKeyChatUser keeps a separate state for each user in each chat. It is the default key.
+func StateKeyChatUser(update *apimodels.Update) (string, bool) {
+	chatID, chatOK := updateChatID(update)
+	userID, userOK := updateUserID(update)
+
+	switch {
+	case chatOK && userOK:
+		return strconv.FormatInt(chatID, 10) + ":" + strconv.FormatInt(userID, 10), true
+	case userOK:
+		return ":" + strconv.FormatInt(userID, 10), true
+	case chatOK:
+		return strconv.FormatInt(chatID, 10) + ":", true
+	}
+
+	return "", false
+}
+
+// StateKeyChat keeps a single state per chat.
+func StateKeyChat(update *apimodels.Update) (string, bool) {
+	id, ok := updateChatID(update)
+	if !ok {
+		return "", false
+	}
+
+	return strconv.FormatInt(id, 10), true
+}
+
+// StateKeyUser keeps a single state per user across all chats.
+func StateKeyUser(update *apimodels.Update) (string, bool) {
+	id, ok := updateUserID(update)
+	if !ok {
+		return "", false
+	}
+
+	return strconv.FormatInt(id, 10), true
+}
+
+// MemoryStateStore keeps states in memory. It is used by default.
+type MemoryStateStore struct {
+	mu     sync.RWMutex
+	states map[string]string
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		states: map[string]string{},
+	}
+}
+
+func (s *MemoryStateStore) GetState(_ context.Context, key string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.states[key], nil
+}
+
+func (s *MemoryStateStore) SetState(_ context.Context, key string, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state == "" {
+		delete(s.states, key)
+	} else {
+		s.states[key] = state
+	}
+
+	return nil
+}
+
+// State returns the group registering routes for the conversation state.
+// While the conversation is in the state, its routes take precedence over the common ones.
+// If no state route matches, the common routes are used.
+//
+// Example:
+//
+//	r.Text("/start", func(ctx *router.Context) {
+//		ctx.LogError1(ctx.SetState("await_name"))
+//	})
+//	r.State("await_name").Text("*", func(ctx *router.Context) {
+//		ctx.LogError1(ctx.ClearState())
+//	})
+//
+// WARNING: this method must be called in the initialization phase.
+func (r *Router) State(name string) *Group {
+	table := r.states[name]
+	if table == nil {
+		table = newRouteTable()
+		r.states[name] = table
+	}
+
+	return &Group{
+		router: r,
+		table:  table,
+	}
+}
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// chatUserUpdate returns the text message of the user in the shared chat.
+func chatUserUpdate(userID int64, text string) *apimodels.Update {
+	res := textUpdate(text)
+	res.Message.From.ID = userID
+
+	return res
+}
+
+func TestRouter_state(t *testing.T) {
+	type step struct {
+		user    int64
+		text    string
+		handler string
+	}
+
+	testCases := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "state route takes precedence",
+			steps: []step{
+				{user: 1, text: "/name bob", handler: "common name"},
+				{user: 1, text: "/start", handler: "start"},
+				{user: 1, text: "/name bob", handler: "state name"},
+			},
+		},
+		{
+			name: "fallback to common routes",
+			steps: []step{
+				{user: 1, text: "/start", handler: "start"},
+				{user: 1, text: "/help", handler: "help"},
+				{user: 1, text: "/name bob", handler: "state name"},
+			},
+		},
+		{
+			name: "clear state",
+			steps: []step{
+				{user: 1, text: "/start", handler: "start"},
+				{user: 1, text: "/name bob", handler: "state name"},
+				{user: 1, text: "/name bob", handler: "common name"},
+			},
+		},
+		{
+			name: "separate users in the chat",
+			steps: []step{
+				{user: 1, text: "/start", handler: "start"},
+				{user: 2, text: "/name bob", handler: "common name"},
+				{user: 1, text: "/name bob", handler: "state name"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handler string
+
+			record := func(name string) Handler {
+				return func(ctx *Context) {
+					ctx.Accept()
+
+					handler = name
+				}
+			}
+
+			router := New()
+			router.Text("/start", func(ctx *Context) {
+				require.NoError(t, ctx.SetState("await_name"))
+			}, record("start"))
+			router.Text("/help", record("help"))
+			router.Text("/name :name", record("common name"))
+			router.State("await_name").Text("/name :name", func(ctx *Context) {
+				require.NoError(t, ctx.ClearState())
+			}, record("state name"))
+
+			for i, step := range tc.steps {
+				handler = ""
+
+				accepted, err := router.Handle(context.Background(), chatUserUpdate(step.user, step.text))
+				require.NoError(t, err, i)
+				require.True(t, accepted, i)
+				require.Equal(t, step.handler, handler, i)
+			}
+		})
+	}
+}
+
+type failingStateStore struct {
+	err error
+}
+
+func (s failingStateStore) GetState(context.Context, string) (string, error) {
+	return "", s.err
+}
+
+func (s failingStateStore) SetState(context.Context, string, string) error {
+	return s.err
+}
+
+func TestRouter_stateError(t *testing.T) {
+	storeErr := errors.New("store failed")
+
+	var handler string
+
+	router := New(WithStateStore(failingStateStore{err: storeErr}))
+	router.Text("/name :name", func(ctx *Context) {
+		ctx.Accept()
+
+		handler = "common name"
+	})
+	router.State("await_name").Text("/name :name", func(ctx *Context) {
+		ctx.Accept()
+
+		handler = "state name"
+	})
+
+	accepted, err := router.Handle(context.Background(), textUpdate("/name bob"))
+	require.ErrorIs(t, err, storeErr)
+	require.True(t, accepted)
+	require.Equal(t, "common name", handler)
+}
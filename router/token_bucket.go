@@ -0,0 +1,98 @@
+package router
+
+import "time"
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// tokenBuckets is a set of token buckets with the same rate and burst.
+// Not safe for concurrent use.
+type tokenBuckets struct {
+	rate      float64
+	burst     float64
+	idle      time.Duration
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// newTokenBuckets creates a set of buckets refilled with rate tokens per second up to burst.
+// Buckets unused for idle are evicted. Idle is never shorter than the time needed to refill the bucket.
+func newTokenBuckets(rate float64, burst int, idle time.Duration) *tokenBuckets {
+	res := &tokenBuckets{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		idle:    idle,
+		buckets: map[string]*tokenBucket{},
+	}
+
+	if res.rate > 0 {
+		res.idle = max(res.idle, time.Duration(res.burst/res.rate*float64(time.Second)))
+	}
+
+	return res
+}
+
+func (s *tokenBuckets) get(key string, now time.Time) *tokenBucket {
+	s.sweep(now)
+
+	bucket := s.buckets[key]
+	if bucket == nil {
+		bucket = &tokenBucket{
+			tokens: s.burst,
+			last:   now,
+		}
+		s.buckets[key] = bucket
+	}
+
+	bucket.tokens = min(s.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*s.rate)
+	bucket.last = now
+
+	return bucket
+}
+
+// allow takes a token if available.
+func (s *tokenBuckets) allow(key string, now time.Time) bool {
+	bucket := s.get(key, now)
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+
+	return true
+}
+
+// reserve takes a token, possibly in debt, and returns the time to wait until the token is available.
+func (s *tokenBuckets) reserve(key string, now time.Time) time.Duration {
+	bucket := s.get(key, now)
+	bucket.tokens--
+
+	if bucket.tokens >= 0 || s.rate <= 0 {
+		return 0
+	}
+
+	wait := time.Duration(-bucket.tokens / s.rate * float64(time.Second))
+
+	// Bucket in debt must not be evicted before the debt is paid.
+	bucket.last = now.Add(wait)
+	bucket.tokens = 0
+
+	return wait
+}
+
+// sweep evicts idle buckets. Idle bucket is full, so it is the same as a new one.
+func (s *tokenBuckets) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.idle {
+		return
+	}
+
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.last) >= s.idle {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBuckets_reserve(t *testing.T) {
+	type step struct {
+		after time.Duration
+		key   string
+		wait  time.Duration
+	}
+
+	testCases := []struct {
+		name  string
+		rate  float64
+		burst int
+		steps []step
+	}{
+		{
+			name:  "burst",
+			rate:  1,
+			burst: 2,
+			steps: []step{
+				{key: "a", wait: 0},
+				{key: "a", wait: 0},
+				{key: "a", wait: time.Second},
+				{key: "b", wait: 0},
+			},
+		},
+		{
+			name:  "debt accumulates",
+			rate:  2,
+			burst: 1,
+			steps: []step{
+				{key: "a", wait: 0},
+				{key: "a", wait: 500 * time.Millisecond},
+				{key: "a", wait: time.Second},
+				{key: "a", wait: 1500 * time.Millisecond},
+			},
+		},
+		{
+			name:  "debt is paid",
+			rate:  1,
+			burst: 1,
+			steps: []step{
+				{key: "a", wait: 0},
+				{key: "a", wait: time.Second},
+				{key: "a", wait: 2 * time.Second},
+				{after: 2 * time.Second, key: "a", wait: time.Second},
+				{after: 2 * time.Second, key: "a", wait: 0},
+			},
+		},
+		{
+			name:  "zero rate",
+			rate:  0,
+			burst: 1,
+			steps: []step{
+				{key: "a", wait: 0},
+				{key: "a", wait: 0},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := newTestClock()
+			buckets := newTokenBuckets(tc.rate, tc.burst, 0)
+
+			for i, step := range tc.steps {
+				clock.Add(step.after)
+				require.Equal(t, step.wait, buckets.reserve(step.key, clock.Now()), "step %d", i)
+			}
+		})
+	}
+}
+
+func TestTokenBuckets_sweepDebt(t *testing.T) {
+	clock := newTestClock()
+	buckets := newTokenBuckets(1, 1, 0)
+
+	require.Zero(t, buckets.reserve("a", clock.Now()))
+
+	for range 10 {
+		buckets.reserve("a", clock.Now())
+	}
+
+	// Bucket in debt is not evicted, otherwise the debt would be forgiven.
+	clock.Add(5 * time.Second)
+	require.Zero(t, buckets.reserve("b", clock.Now()))
+	require.Equal(t, 6*time.Second, buckets.reserve("a", clock.Now()))
+}
@@ -0,0 +1,241 @@
+package router
+
+import (
+	"github.com/go-telegram/bot/models"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+)
+
+// updateMessage returns the message carried by the update, if any.
+func updateMessage(update *apimodels.Update) *models.Message {
+	switch {
+	case update.Message != nil:
+		return update.Message
+	case update.EditedMessage != nil:
+		return update.EditedMessage
+	case update.ChannelPost != nil:
+		return update.ChannelPost
+	case update.EditedChannelPost != nil:
+		return update.EditedChannelPost
+	case update.BusinessMessage != nil:
+		return update.BusinessMessage
+	case update.EditedBusinessMessage != nil:
+		return update.EditedBusinessMessage
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.Message.Message
+	}
+
+	return nil
+}
+
+// updateChatID returns the ID of the chat where the update happened.
+func updateChatID(update *apimodels.Update) (int64, bool) {
+	if msg := updateMessage(update); msg != nil {
+		return msg.Chat.ID, true
+	}
+
+	switch {
+	case update.CallbackQuery != nil && update.CallbackQuery.Message.InaccessibleMessage != nil:
+		return update.CallbackQuery.Message.InaccessibleMessage.Chat.ID, true
+	case update.MessageReaction != nil:
+		return update.MessageReaction.Chat.ID, true
+	case update.MessageReactionCount != nil:
+		return update.MessageReactionCount.Chat.ID, true
+	case update.MyChatMember != nil:
+		return update.MyChatMember.Chat.ID, true
+	case update.ChatMember != nil:
+		return update.ChatMember.Chat.ID, true
+	case update.ChatJoinRequest != nil:
+		return update.ChatJoinRequest.Chat.ID, true
+	case update.ChatBoost != nil:
+		return update.ChatBoost.Chat.ID, true
+	case update.RemovedChatBoost != nil:
+		return update.RemovedChatBoost.Chat.ID, true
+	case update.DeletedBusinessMessages != nil:
+		return update.DeletedBusinessMessages.Chat.ID, true
+	}
+
+	return 0, false
+}
+
+// updateUserID returns the ID of the user who initiated the update.
+//
+//nolint:cyclop
+func updateUserID(update *apimodels.Update) (int64, bool) {
+	var user *models.User
+
+	switch {
+	case update.CallbackQuery != nil:
+		user = &update.CallbackQuery.From
+	case update.InlineQuery != nil:
+		user = update.InlineQuery.From
+	case update.ChosenInlineResult != nil:
+		user = &update.ChosenInlineResult.From
+	case update.ShippingQuery != nil:
+		user = update.ShippingQuery.From
+	case update.PreCheckoutQuery != nil:
+		user = update.PreCheckoutQuery.From
+	case update.PollAnswer != nil:
+		user = update.PollAnswer.User
+	case update.MyChatMember != nil:
+		user = &update.MyChatMember.From
+	case update.ChatMember != nil:
+		user = &update.ChatMember.From
+	case update.ChatJoinRequest != nil:
+		user = &update.ChatJoinRequest.From
+	case update.MessageReaction != nil:
+		user = update.MessageReaction.User
+	case update.PurchasedPaidMedia != nil:
+		user = &update.PurchasedPaidMedia.From
+	default:
+		if msg := updateMessage(update); msg != nil {
+			user = msg.From
+		}
+	}
+
+	if user == nil {
+		return 0, false
+	}
+
+	return user.ID, true
+}
+
+// UpdateKind is the name of the update field that carries the payload.
+// Values match the names used in allowed_updates.
+type UpdateKind string
+
+const (
+	KindUnknown                 UpdateKind = ""
+	KindMessage                 UpdateKind = "message"
+	KindEditedMessage           UpdateKind = "edited_message"
+	KindChannelPost             UpdateKind = "channel_post"
+	KindEditedChannelPost       UpdateKind = "edited_channel_post"
+	KindBusinessConnection      UpdateKind = "business_connection"
+	KindBusinessMessage         UpdateKind = "business_message"
+	KindEditedBusinessMessage   UpdateKind = "edited_business_message"
+	KindDeletedBusinessMessages UpdateKind = "deleted_business_messages"
+	KindMessageReaction         UpdateKind = "message_reaction"
+	KindMessageReactionCount    UpdateKind = "message_reaction_count"
+	KindInlineQuery             UpdateKind = "inline_query"
+	KindChosenInlineResult      UpdateKind = "chosen_inline_result"
+	KindCallbackQuery           UpdateKind = "callback_query"
+	KindShippingQuery           UpdateKind = "shipping_query"
+	KindPreCheckoutQuery        UpdateKind = "pre_checkout_query"
+	KindPurchasedPaidMedia      UpdateKind = "purchased_paid_media"
+	KindPoll                    UpdateKind = "poll"
+	KindPollAnswer              UpdateKind = "poll_answer"
+	KindMyChatMember            UpdateKind = "my_chat_member"
+	KindChatMember              UpdateKind = "chat_member"
+	KindChatJoinRequest         UpdateKind = "chat_join_request"
+	KindChatBoost               UpdateKind = "chat_boost"
+	KindRemovedChatBoost        UpdateKind = "removed_chat_boost"
+)
+
+func (k UpdateKind) String() string {
+	return string(k)
+}
+
+// UpdateKindOf returns the kind of the update.
+//
+//nolint:cyclop,gocyclo
+func UpdateKindOf(update *apimodels.Update) UpdateKind {
+	switch {
+	case update.Message != nil:
+		return KindMessage
+	case update.EditedMessage != nil:
+		return KindEditedMessage
+	case update.ChannelPost != nil:
+		return KindChannelPost
+	case update.EditedChannelPost != nil:
+		return KindEditedChannelPost
+	case update.BusinessConnection != nil:
+		return KindBusinessConnection
+	case update.BusinessMessage != nil:
+		return KindBusinessMessage
+	case update.EditedBusinessMessage != nil:
+		return KindEditedBusinessMessage
+	case update.DeletedBusinessMessages != nil:
+		return KindDeletedBusinessMessages
+	case update.MessageReaction != nil:
+		return KindMessageReaction
+	case update.MessageReactionCount != nil:
+		return KindMessageReactionCount
+	case update.InlineQuery != nil:
+		return KindInlineQuery
+	case update.ChosenInlineResult != nil:
+		return KindChosenInlineResult
+	case update.CallbackQuery != nil:
+		return KindCallbackQuery
+	case update.ShippingQuery != nil:
+		return KindShippingQuery
+	case update.PreCheckoutQuery != nil:
+		return KindPreCheckoutQuery
+	case update.PurchasedPaidMedia != nil:
+		return KindPurchasedPaidMedia
+	case update.Poll != nil:
+		return KindPoll
+	case update.PollAnswer != nil:
+		return KindPollAnswer
+	case update.MyChatMember != nil:
+		return KindMyChatMember
+	case update.ChatMember != nil:
+		return KindChatMember
+	case update.ChatJoinRequest != nil:
+		return KindChatJoinRequest
+	case update.ChatBoost != nil:
+		return KindChatBoost
+	case update.RemovedChatBoost != nil:
+		return KindRemovedChatBoost
+	}
+
+	return KindUnknown
+}
+
+// isMessageKind reports whether the update of the kind has a message.
+func isMessageKind(kind UpdateKind) bool {
+	switch kind { //nolint:exhaustive
+	case KindMessage, KindEditedMessage,
+		KindChannelPost, KindEditedChannelPost,
+		KindBusinessMessage, KindEditedBusinessMessage:
+		return true
+	default:
+		return false
+	}
+}
+
+// isNormalizedKind reports whether the text of the kind is typed by a user and normalized by WithNormalizer.
+// Callback data and invoice payloads are generated by the bot and kept as is.
+func isNormalizedKind(kind UpdateKind) bool {
+	return isMessageKind(kind) || kind == KindInlineQuery || kind == KindChosenInlineResult
+}
+
+// updateText returns the text used for pattern matching:
+// message text, callback data, inline query, or invoice payload.
+// Returns nil for kinds without text.
+func updateText(update *apimodels.Update) *string {
+	switch {
+	case update.Message != nil:
+		return &update.Message.Text
+	case update.EditedMessage != nil:
+		return &update.EditedMessage.Text
+	case update.ChannelPost != nil:
+		return &update.ChannelPost.Text
+	case update.EditedChannelPost != nil:
+		return &update.EditedChannelPost.Text
+	case update.BusinessMessage != nil:
+		return &update.BusinessMessage.Text
+	case update.EditedBusinessMessage != nil:
+		return &update.EditedBusinessMessage.Text
+	case update.CallbackQuery != nil:
+		return &update.CallbackQuery.Data
+	case update.InlineQuery != nil:
+		return &update.InlineQuery.Query
+	case update.ChosenInlineResult != nil:
+		return &update.ChosenInlineResult.Query
+	case update.ShippingQuery != nil:
+		return &update.ShippingQuery.InvoicePayload
+	case update.PreCheckoutQuery != nil:
+		return &update.PreCheckoutQuery.InvoicePayload
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/opoccomaxao/tg-instrumentation/apimodels"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_SetWebhook(t *testing.T) {
+	var secrets []string
+
+	client, _ := newTestBot(t, func(writer http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseMultipartForm(1<<20))
+
+		secrets = append(secrets, req.FormValue("secret_token"))
+
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	router := New(WithClient(client), WithSecretToken("secret"))
+
+	params := &bot.SetWebhookParams{URL: "https://example.com/hook"}
+	require.NoError(t, router.SetWebhook(context.Background(), params))
+	require.Empty(t, params.SecretToken, "caller params must not be modified")
+
+	params = &bot.SetWebhookParams{URL: "https://example.com/hook", SecretToken: "own"}
+	require.NoError(t, router.SetWebhook(context.Background(), params))
+	require.Equal(t, "own", params.SecretToken)
+
+	require.Equal(t, []string{"secret", "own"}, secrets)
+}
+
+func TestRouter_webhookSecretToken(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header []string
+		body   string
+		status int
+	}{
+		{name: "missing", body: "{", status: http.StatusUnauthorized},
+		{name: "wrong", header: []string{"wrong"}, body: "{", status: http.StatusUnauthorized},
+		{name: "prefix", header: []string{"secre"}, body: "{", status: http.StatusUnauthorized},
+		{name: "invalid body", header: []string{"secret"}, body: "{", status: http.StatusBadRequest},
+		{name: "valid", header: []string{"secret"}, body: `{"update_id":1}`, status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handled int
+
+			router := New(WithSecretToken("secret"))
+			handler := router.WebhookHandler(updateHandlerFunc(func(context.Context, *apimodels.Update) (bool, error) {
+				handled++
+
+				return true, nil
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			for _, value := range tc.header {
+				req.Header.Set(secretTokenHeader, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler(recorder, req)
+
+			require.Equal(t, tc.status, recorder.Code)
+
+			if tc.status == http.StatusOK {
+				require.Equal(t, 1, handled)
+			} else {
+				require.Zero(t, handled)
+			}
+		})
+	}
+}
+
+func TestRouter_webhookStatus(t *testing.T) {
+	failed := errors.New("failed")
+
+	testCases := []struct {
+		name     string
+		accepted bool
+		err      error
+		status   int
+	}{
+		{name: "accepted", accepted: true, status: http.StatusOK},
+		// Accepted update must not be redelivered by Telegram, even if some handlers failed.
+		{name: "accepted with errors", accepted: true, err: failed, status: http.StatusOK},
+		{name: "failed", err: failed, status: http.StatusInternalServerError},
+		{name: "not accepted", status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := New()
+			handler := router.WebhookHandler(updateHandlerFunc(func(context.Context, *apimodels.Update) (bool, error) {
+				return tc.accepted, tc.err
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"update_id":1}`))
+			recorder := httptest.NewRecorder()
+			handler(recorder, req)
+
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
+
+func TestRouter_HandlerFunc(t *testing.T) {
+	router := New()
+	router.Text("/fail", func(ctx *Context) {
+		ctx.Accept()
+		ctx.Error(errors.New("failed"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"update_id":1,"message":{"text":"/fail","chat":{"id":1}}}`))
+	recorder := httptest.NewRecorder()
+	router.HandlerFunc(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,5 @@
+package storage
+
+import "errors"
+
+var ErrFailed = errors.New("failed")
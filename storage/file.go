@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// File keeps values in memory and writes all of them to a JSON file on each change.
+// It is intended for single-instance deployments with small amount of data.
+type File struct {
+	*Memory
+
+	path string
+}
+
+// NewFile loads values from the file. Missing file is treated as empty storage.
+func NewFile(path string) (*File, error) {
+	res := &File{
+		Memory: NewMemory(),
+		path:   path,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, nil
+		}
+
+		return nil, errors.WithStack(err)
+	}
+
+	if len(data) == 0 {
+		return res, nil
+	}
+
+	err = json.Unmarshal(data, &res.items)
+	if err != nil {
+		return nil, errors.Wrapf(ErrFailed, "invalid storage file %s: %v", path, err)
+	}
+
+	return res, nil
+}
+
+func (f *File) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.set(key, value, ttl)
+
+	return f.flush()
+}
+
+func (f *File) Delete(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.items[key]; !ok {
+		return nil
+	}
+
+	delete(f.items, key)
+
+	return f.flush()
+}
+
+func (f *File) flush() error {
+	data, err := json.Marshal(f.items)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return WriteFileAtomic(f.path, data)
+}
+
+// WriteFileAtomic writes the data to a temporary file in the same directory and renames it,
+// so a crash never leaves a truncated file.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return errors.WithStack(err)
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return errors.WithStack(err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type item struct {
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (i *item) expired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt)
+}
+
+// Memory keeps values in memory. Expired values are evicted periodically.
+type Memory struct {
+	mu        sync.RWMutex
+	items     map[string]item
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		items: map[string]item{},
+		now:   time.Now,
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	value, ok := m.items[key]
+	if !ok || value.expired(m.now()) {
+		return nil, false, nil
+	}
+
+	return slices.Clone(value.Value), true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(key, value, ttl)
+
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.items, key)
+
+	return nil
+}
+
+func (m *Memory) set(key string, value []byte, ttl time.Duration) {
+	now := m.now()
+	m.sweep(now)
+
+	res := item{
+		Value: slices.Clone(value),
+	}
+
+	if ttl > 0 {
+		res.ExpiresAt = now.Add(ttl)
+	}
+
+	m.items[key] = res
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < memorySweepInterval {
+		return
+	}
+
+	m.lastSweep = now
+
+	for key, value := range m.items {
+		if value.expired(now) {
+			delete(m.items, key)
+		}
+	}
+}
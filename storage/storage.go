@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Storage is a key-value storage with optional expiration.
+type Storage interface {
+	// Get returns the value and true, or false if the key is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value. Zero ttl means no expiration.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the key. Missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+
+	store := NewMemory()
+	store.now = func() time.Time { return now }
+
+	_, ok, err := store.Get(ctx, "missing")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, store.Set(ctx, "forever", []byte("a"), 0))
+	require.NoError(t, store.Set(ctx, "short", []byte("b"), time.Second))
+
+	value, ok, err := store.Get(ctx, "short")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("b"), value)
+
+	now = now.Add(time.Second)
+
+	_, ok, err = store.Get(ctx, "short")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	value, ok, err = store.Get(ctx, "forever")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("a"), value)
+
+	require.NoError(t, store.Delete(ctx, "forever"))
+
+	_, ok, err = store.Get(ctx, "forever")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store, err := NewFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set(ctx, "key", []byte("value"), time.Hour))
+	require.NoError(t, store.Set(ctx, "deleted", []byte("value"), 0))
+	require.NoError(t, store.Delete(ctx, "deleted"))
+
+	reopened, err := NewFile(path)
+	require.NoError(t, err)
+
+	value, ok, err := reopened.Get(ctx, "key")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("value"), value)
+
+	_, ok, err = reopened.Get(ctx, "deleted")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("first")))
+	require.NoError(t, WriteFileAtomic(path, []byte("second")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files must be renamed")
+
+	require.Error(t, WriteFileAtomic(filepath.Join(dir, "missing", "data"), nil))
+}
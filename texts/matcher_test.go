@@ -69,3 +69,194 @@ func TestMatcher_Match(t *testing.T) {
 		})
 	}
 }
+
+func TestMatcher_MatchCaptures(t *testing.T) {
+	testCases := []struct {
+		value    string
+		pattern  SimplePattern
+		result   int
+		names    []string
+		captures []string
+		err      string
+	}{
+		{
+			value:    "/article 42",
+			pattern:  "/article :id",
+			result:   11,
+			names:    []string{"id"},
+			captures: []string{"42"},
+		},
+		{
+			value:    "/article 42 page=2",
+			pattern:  "/article :id",
+			result:   11,
+			names:    []string{"id"},
+			captures: []string{"42"},
+		},
+		{
+			value:   "/article ",
+			pattern: "/article :id",
+			result:  -1,
+			names:   []string{"id"},
+		},
+		{
+			value:    "/user/42/edit",
+			pattern:  "/user/:id/edit",
+			result:   13,
+			names:    []string{"id"},
+			captures: []string{"42"},
+		},
+		{
+			value:   "/user/42/x/edit",
+			pattern: "/user/:id/edit",
+			result:  -1,
+			names:   []string{"id"},
+		},
+		{
+			value:    "/user/42/x/edit",
+			pattern:  "/user/*",
+			result:   15,
+			names:    []string{""},
+			captures: []string{"42/x/edit"},
+		},
+		{
+			value:    "/a/x/b/y/c",
+			pattern:  "/a/*/b/:name/*",
+			result:   10,
+			names:    []string{"", "name", ""},
+			captures: []string{"x", "y", "c"},
+		},
+		{
+			value:    "/axbyb",
+			pattern:  "/a*b$",
+			result:   6,
+			names:    []string{""},
+			captures: []string{"xby"},
+		},
+		{
+			value:   "/a 1 2",
+			pattern: "/a :id$",
+			result:  -1,
+			names:   []string{"id"},
+		},
+		{
+			value:   "at 10:30",
+			pattern: "at 10:30",
+			result:  8,
+		},
+		{
+			value:   "settings:lang",
+			pattern: "settings:lang",
+			result:  13,
+		},
+		{
+			value:   "settingsfoo",
+			pattern: "settings:lang",
+			result:  -1,
+		},
+		{
+			value:   "/set lang:en",
+			pattern: "/set lang:en",
+			result:  12,
+		},
+		{
+			value:    "/x:b",
+			pattern:  "/:a:b",
+			result:   4,
+			names:    []string{"a"},
+			captures: []string{"x"},
+		},
+		{
+			value:   "",
+			pattern: "/:a*",
+			err:     "captures must be separated by text in pattern: /:a*",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.pattern.String(), func(t *testing.T) {
+			matcher, err := NewSimpleMatcher(tC.pattern)
+			if tC.err != "" {
+				require.ErrorContains(t, err, tC.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tC.names, matcher.Names())
+
+			result, captures := matcher.MatchCaptures(tC.value, nil)
+			require.Equal(t, tC.result, result)
+			require.Equal(t, tC.captures, captures)
+
+			result, indexes := matcher.MatchIndexes(tC.value, nil)
+			require.Equal(t, tC.result, result)
+			require.Equal(t, tC.captures, appendCaptures(nil, tC.value, indexes))
+		})
+	}
+}
+
+func TestRegexpMatcher_MatchCaptures(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expr     string
+		result   int
+		names    []string
+		captures []string
+		err      string
+	}{
+		{
+			value:    "/order 12-345",
+			expr:     `^/order (?P<year>\d+)-(?P<num>\d+)$`,
+			result:   13,
+			names:    []string{"year", "num"},
+			captures: []string{"12", "345"},
+		},
+		{
+			value:    "nice #golang post",
+			expr:     `#(?P<tag>\w+)`,
+			result:   12,
+			names:    []string{"tag"},
+			captures: []string{"golang"},
+		},
+		{
+			value:    "ab",
+			expr:     `a(x)?(b)`,
+			result:   2,
+			names:    []string{"", ""},
+			captures: []string{"", "b"},
+		},
+		{
+			value:  "/order x",
+			expr:   `^/order \d+`,
+			result: -1,
+			names:  []string{},
+		},
+		{
+			expr: `(`,
+			err:  "invalid pattern",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.expr, func(t *testing.T) {
+			matcher, err := NewRegexpMatcher(tC.expr)
+			if tC.err != "" {
+				require.ErrorContains(t, err, tC.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tC.names, matcher.Names())
+
+			result, captures := matcher.MatchCaptures(tC.value, nil)
+			require.Equal(t, tC.result, result)
+			require.Equal(t, tC.captures, captures)
+
+			result, indexes := matcher.MatchIndexes(tC.value, nil)
+			require.Equal(t, tC.result, result)
+			require.Equal(t, tC.captures, appendCaptures(nil, tC.value, indexes))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package texts
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization is a set of text normalization steps for NewNormalizer.
+type Normalization uint8
+
+const (
+	// NormalizeUnicode applies NFKC normalization: compatibility characters
+	// like fullwidth letters and ligatures are replaced with the usual ones.
+	NormalizeUnicode Normalization = 1 << iota
+	// NormalizeSpaces trims the text and collapses whitespace sequences,
+	// including non-breaking and zero-width spaces, into one space.
+	NormalizeSpaces
+	// NormalizeCase applies Unicode case folding, so "/Start" and "/START" become "/start".
+	NormalizeCase
+
+	NormalizeAll = NormalizeUnicode | NormalizeSpaces | NormalizeCase
+)
+
+//nolint:gochecknoglobals
+var spacesRegexp = regexp.MustCompile(`[\s\p{Zs}\x{200B}\x{FEFF}]+`)
+
+// NewNormalizer returns the replacer applying the normalization steps
+// in the order: Unicode, spaces, case.
+func NewNormalizer(normalization Normalization) *Replacer {
+	res := NewReplacer()
+
+	if normalization&NormalizeUnicode != 0 {
+		res.AddFunc(norm.NFKC.String)
+	}
+
+	if normalization&NormalizeSpaces != 0 {
+		res.AddRegexp(spacesRegexp, " ")
+		res.AddFunc(strings.TrimSpace)
+	}
+
+	if normalization&NormalizeCase != 0 {
+		res.AddFunc(func(value string) string {
+			return cases.Fold().String(value)
+		})
+	}
+
+	return res
+}
+
+// Normalize applies the replacer to the text of the pattern.
+// Names of params are kept as is.
+func (p SimplePattern) Normalize(replacer TextReplacer) SimplePattern {
+	_, names, err := p.parse()
+	if err != nil {
+		return p
+	}
+
+	res := SimplePattern(replacer.Execute(string(p)))
+
+	parts, normalizedNames, err := res.parse()
+	if err != nil || len(names) != len(normalizedNames) {
+		return res
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(parts[0])
+
+	for i, name := range names {
+		if name == "" {
+			builder.WriteByte(wildcardChar)
+		} else {
+			builder.WriteByte(paramChar)
+			builder.WriteString(name)
+		}
+
+		builder.WriteString(parts[i+1])
+	}
+
+	if res.IsSuffix() {
+		builder.WriteByte(suffixChar)
+	}
+
+	return SimplePattern(builder.String())
+}
+
+// OriginalSpan returns the span of the original text that the replacer turns into
+// the span [start, end) of replacer.Execute(original), e.g. to take a capture
+// matched in the normalized text from the text typed by a user.
+//
+// Positions are found by replacing prefixes of the original, so it fits replacers
+// that change the text locally and keep its order, like the normalizers of NewNormalizer.
+func OriginalSpan(replacer TextReplacer, original string, start int, end int) (int, int) {
+	offsets := make([]int, 0, len(original)+1)
+	for i := range original {
+		offsets = append(offsets, i)
+	}
+
+	offsets = append(offsets, len(original))
+
+	replaced := func(i int) int {
+		return len(replacer.Execute(original[:offsets[i]]))
+	}
+
+	// The last prefix not longer than start skips the text removed before the span.
+	first := max(sort.Search(len(offsets), func(i int) bool { return replaced(i) > start })-1, 0)
+	// The first prefix reaching end includes the whole span.
+	last := min(sort.Search(len(offsets), func(i int) bool { return replaced(i) >= end }), len(offsets)-1)
+
+	return offsets[first], max(offsets[first], offsets[last])
+}
@@ -0,0 +1,80 @@
+package texts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNormalizer(t *testing.T) {
+	testCases := []struct {
+		normalization Normalization
+		input         string
+		output        string
+	}{
+		{
+			normalization: NormalizeCase,
+			input:         "/START Straße",
+			output:        "/start strasse",
+		},
+		{
+			normalization: NormalizeSpaces,
+			input:         "  /start \t\u00a0\u200b now\n",
+			output:        "/start now",
+		},
+		{
+			normalization: NormalizeUnicode,
+			input:         "／ｓｔａｒｔ ﬁle",
+			output:        "/start file",
+		},
+		{
+			normalization: NormalizeAll,
+			input:         "  ／ＳＴＡＲＴ  Now ",
+			output:        "/start now",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.input, func(t *testing.T) {
+			require.Equal(t, tC.output, NewNormalizer(tC.normalization).Execute(tC.input))
+		})
+	}
+}
+
+func TestSimplePattern_Normalize(t *testing.T) {
+	normalizer := NewNormalizer(NormalizeAll)
+
+	require.Equal(t, SimplePattern("/article :ID"), SimplePattern("/Article  :ID").Normalize(normalizer))
+	require.Equal(t, SimplePattern("/user/*/edit$"), SimplePattern("/User/*/Edit$").Normalize(normalizer))
+	require.Equal(t, SimplePattern("at 10:30"), SimplePattern("AT 10:30").Normalize(normalizer))
+}
+
+func TestOriginalSpan(t *testing.T) {
+	normalizer := NewNormalizer(NormalizeAll)
+
+	testCases := []struct {
+		original string
+		span     string
+		result   string
+	}{
+		{original: "/Article 42 Q=Hello", span: "q=hello", result: "Q=Hello"},
+		{original: "  /Article   Foo  Bar ", span: "foo bar", result: "Foo  Bar"},
+		{original: "/ＳＴＡＲＴ  Ｎｏｗ", span: "now", result: "Ｎｏｗ"},
+		{original: "/start Straße x", span: "strasse", result: "Straße"},
+		{original: "/start \u200bAlice\u00a0Smith", span: "alice smith", result: "Alice\u00a0Smith"},
+		{original: "/start", span: "", result: ""},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.original, func(t *testing.T) {
+			normalized := normalizer.Execute(tC.original)
+
+			start := strings.Index(normalized, tC.span)
+			require.GreaterOrEqual(t, start, 0)
+
+			first, last := OriginalSpan(normalizer, tC.original, start, start+len(tC.span))
+			require.Equal(t, tC.result, tC.original[first:last])
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package texts
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// RegexpMatcher is a regular expression matcher.
+//
+// The expression is not anchored, use `^` and `$` explicitly.
+// Groups are captured, unnamed groups have empty names.
+type RegexpMatcher struct {
+	expr  *regexp.Regexp
+	names []string
+}
+
+func NewRegexpMatcher(expr string) (*RegexpMatcher, error) {
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidPattern, "%s: %v", expr, err)
+	}
+
+	return &RegexpMatcher{
+		expr:  compiled,
+		names: compiled.SubexpNames()[1:],
+	}, nil
+}
+
+// Names returns the names of groups in order, empty string for unnamed groups.
+func (m *RegexpMatcher) Names() []string {
+	return m.names
+}
+
+// String returns the source of the expression.
+func (m *RegexpMatcher) String() string {
+	return m.expr.String()
+}
+
+// Match checks if the value matches the expression.
+//
+// Returns the end of the leftmost match, so the score is comparable with SimpleMatcher,
+// or -1 if the value does not match the expression.
+func (m *RegexpMatcher) Match(value string) int {
+	res, _ := m.MatchCaptures(value, nil)
+
+	return res
+}
+
+// MatchCaptures checks if the value matches the expression and appends the groups to dst,
+// one for each of Names. Groups that did not participate in the match are empty.
+//
+// Returns the end of the leftmost match or -1 if the value does not match the expression,
+// dst is returned without captures in this case.
+func (m *RegexpMatcher) MatchCaptures(value string, dst []string) (int, []string) {
+	loc := m.expr.FindStringSubmatchIndex(value)
+	if loc == nil {
+		return -1, dst
+	}
+
+	return loc[1], appendCaptures(dst, value, loc[2:])
+}
+
+// MatchIndexes is MatchCaptures appending the start and the end of each group in the value to dst,
+// -1 for groups that did not participate in the match.
+func (m *RegexpMatcher) MatchIndexes(value string, dst []int) (int, []int) {
+	loc := m.expr.FindStringSubmatchIndex(value)
+	if loc == nil {
+		return -1, dst
+	}
+
+	return loc[1], append(dst, loc[2:]...)
+}
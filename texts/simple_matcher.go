@@ -11,8 +11,10 @@ import (
 // It supports the following special characters:
 // - `* - matches any sequence of characters.
 // - `$` - matches the end of the string.
+// - `:name` - matches a non-empty segment up to the next space or `/`.
 type SimpleMatcher struct {
 	parts  []string
+	names  []string
 	suffix bool
 	greedy bool
 	minLen int
@@ -23,7 +25,10 @@ func NewSimpleMatcherFromString(pattern string) (*SimpleMatcher, error) {
 }
 
 func NewSimpleMatcher(pattern SimplePattern) (*SimpleMatcher, error) {
-	var res SimpleMatcher
+	var (
+		res SimpleMatcher
+		err error
+	)
 
 	res.minLen = pattern.MinLength()
 	res.suffix = pattern.IsSuffix()
@@ -33,7 +38,10 @@ func NewSimpleMatcher(pattern SimplePattern) (*SimpleMatcher, error) {
 		return nil, errors.Wrapf(ErrInvalidPattern, "more than one $ in pattern: %s", pattern)
 	}
 
-	res.parts = pattern.Parts()
+	res.parts, res.names, err = pattern.parse()
+	if err != nil {
+		return nil, err
+	}
 
 	if len(res.parts) == 0 {
 		return nil, errors.Wrap(ErrFailed, pattern.String())
@@ -42,45 +50,122 @@ func NewSimpleMatcher(pattern SimplePattern) (*SimpleMatcher, error) {
 	return &res, nil
 }
 
+// Names returns the names of captures in order, empty string for wildcards.
+func (m *SimpleMatcher) Names() []string {
+	return m.names
+}
+
+// Prefix returns the text every matching value starts with.
+func (m *SimpleMatcher) Prefix() string {
+	return m.parts[0]
+}
+
 // Match checks if the value matches the pattern.
 //
 // Returns the length of the matched prefix or -1 if the value does not match the pattern.
 func (m *SimpleMatcher) Match(value string) int {
+	res, _ := m.MatchCaptures(value, nil)
+
+	return res
+}
+
+// MatchCaptures checks if the value matches the pattern and appends the captured values to dst,
+// one for each of Names. Wildcard in the end of the pattern captures the rest of the value.
+//
+// Returns the length of the matched prefix or -1 if the value does not match the pattern,
+// dst is returned without captures in this case.
+func (m *SimpleMatcher) MatchCaptures(value string, dst []string) (int, []string) {
+	var buffer [16]int //nolint:mnd
+
+	res, indexes := m.MatchIndexes(value, buffer[:0])
+
+	return res, appendCaptures(dst, value, indexes)
+}
+
+// MatchIndexes is MatchCaptures appending the start and the end of each capture in the value to dst.
+func (m *SimpleMatcher) MatchIndexes(value string, dst []int) (int, []int) {
 	if len(value) < m.minLen {
-		return -1
+		return -1, dst
 	}
 
 	// First part is prefix.
-	index := strings.Index(value, m.parts[0])
-	if index != 0 {
-		return -1
+	if !strings.HasPrefix(value, m.parts[0]) {
+		return -1, dst
 	}
 
 	matched := len(m.parts[0])
 	if len(m.parts) == 1 {
-		return matched
+		return matched, dst
 	}
 
-	for _, part := range m.parts[1:] {
+	indexes := dst
+
+	for i, part := range m.parts[1:] {
+		start := matched
+
 		idx := strings.Index(value[matched:], part)
 		if idx == -1 {
-			return -1
+			return -1, dst
+		}
+
+		if m.names[i] != "" {
+			segment := strings.IndexAny(value[matched:], paramSeparators)
+			if segment == -1 {
+				segment = len(value) - matched
+			}
+
+			if part == "" {
+				idx = segment
+			}
+
+			if idx == 0 || idx > segment {
+				return -1, dst
+			}
 		}
 
 		matched += idx + len(part)
+		indexes = append(indexes, start, start+idx)
 	}
 
+	lastEnd, lastName := len(indexes)-1, m.names[len(m.names)-1]
+
 	if m.suffix {
-		if !strings.HasSuffix(value, m.parts[len(m.parts)-1]) {
-			return -1
+		lastPart := m.parts[len(m.parts)-1]
+		if !strings.HasSuffix(value, lastPart) {
+			return -1, dst
+		}
+
+		if lastName != "" && matched != len(value) {
+			return -1, dst
+		}
+
+		if lastName == "" {
+			indexes[lastEnd] = len(value) - len(lastPart)
 		}
 
-		return len(value)
+		return len(value), indexes
 	}
 
 	if m.greedy {
-		return len(value)
+		indexes[lastEnd] = len(value)
+
+		return len(value), indexes
+	}
+
+	return matched, indexes
+}
+
+// appendCaptures appends the values of captures by their indexes, empty for negative indexes.
+func appendCaptures(dst []string, value string, indexes []int) []string {
+	for i := 0; i+1 < len(indexes); i += 2 {
+		if indexes[i] < 0 {
+			dst = append(dst, "")
+
+			continue
+		}
+
+		dst = append(dst, value[indexes[i]:indexes[i+1]])
 	}
 
-	return matched
+	return dst
 }
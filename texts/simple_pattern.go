@@ -1,19 +1,45 @@
 package texts
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	wildcardChar    = '*'
+	paramChar       = ':'
+	suffixChar      = '$'
+	paramSeparators = " /"
+)
 
 // SimplePattern is a simple pattern for matching strings.
 //
 // It supports the following special characters:
 // - `* - matches any sequence of characters.
 // - `$` - matches the end of the string.
+// - `:name` - matches a non-empty segment up to the next space or `/`.
+// The param starts a segment: it goes first or after a space or `/`, and the name starts with a letter or `_`,
+// so `10:30` and `settings:lang` are plain text.
+//
+// Wildcards and named params are captured, see SimpleMatcher.MatchCaptures.
 type SimplePattern string
 
 func (p SimplePattern) MinLength() int {
-	constants := strings.ReplaceAll(string(p), "*", "")
-	constants = strings.ReplaceAll(constants, "$", "")
+	parts, names, _ := p.parse()
 
-	return len(constants)
+	res := 0
+	for _, part := range parts {
+		res += len(part)
+	}
+
+	for _, name := range names {
+		if name != "" {
+			res++
+		}
+	}
+
+	return res
 }
 
 func (p SimplePattern) IsSuffix() bool {
@@ -24,14 +50,74 @@ func (p SimplePattern) IsGreedy() bool {
 	return strings.HasSuffix(string(p), "*")
 }
 
+// Parts returns the text between wildcards and named params.
 func (p SimplePattern) Parts() []string {
-	pattern := string(p)
-	pattern = strings.ReplaceAll(pattern, "$", "")
-	parts := strings.Split(pattern, "*")
+	parts, _, _ := p.parse()
 
 	return parts
 }
 
+// Names returns the names of captures in order, empty string for wildcards.
+func (p SimplePattern) Names() []string {
+	_, names, _ := p.parse()
+
+	return names
+}
+
 func (p SimplePattern) String() string {
 	return string(p)
 }
+
+// parse splits the pattern into text parts and captures between them.
+// There is always one part more than captures.
+//
+//nolint:nonamedreturns
+func (p SimplePattern) parse() (parts []string, names []string, err error) {
+	pattern := strings.ReplaceAll(string(p), "$", "")
+	start := 0
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == wildcardChar:
+			parts = append(parts, pattern[start:i])
+			names = append(names, "")
+			start = i + 1
+		case pattern[i] == paramChar && isSegmentStart(pattern, i) && i+1 < len(pattern) && isParamStart(pattern[i+1]):
+			end := i + 1
+			for end < len(pattern) && isParamChar(pattern[end]) {
+				end++
+			}
+
+			parts = append(parts, pattern[start:i])
+			names = append(names, pattern[i+1:end])
+			start = end
+			i = end - 1
+		}
+	}
+
+	parts = append(parts, pattern[start:])
+
+	for i, name := range names {
+		if parts[i+1] != "" || i+1 == len(names) {
+			continue
+		}
+
+		if name != "" || names[i+1] != "" {
+			err = errors.Wrapf(ErrInvalidPattern, "captures must be separated by text in pattern: %s", p)
+		}
+	}
+
+	return parts, names, err
+}
+
+func isSegmentStart(pattern string, i int) bool {
+	return i == 0 || strings.IndexByte(paramSeparators, pattern[i-1]) >= 0
+}
+
+func isParamStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isParamChar(c byte) bool {
+	return isParamStart(c) || ('0' <= c && c <= '9')
+}